@@ -14,21 +14,118 @@ type Column struct {
 	JTag        string
 	Type        reflect.Type
 	TypeDefine  string
-	Indexed     bool // Indexed or not, on field
-	Nullable    bool // Nullable constraint on field
-	Unique      bool // Unique constraint on field
-	PrimaryKey  bool //Primary Key constraint on field
+	Indexed     bool   // Indexed or not, on field
+	Nullable    bool   // Nullable constraint on field
+	Unique      bool   // Unique constraint on field
+	PrimaryKey  bool   //Primary Key constraint on field
+	Always      bool   // Always written on insert/update, even if empty
+	Generated   bool   // Generated by database, never written
+	SoftDelete  bool   // Deleted time of soft deleted rows, NULL if not deleted
+	AutoCreate  bool   // Filled with current time on insert
+	AutoUpdate  bool   // Filled with current time on insert and every update
+	Version     bool   // Version for optimistic locking, increased by every update of entity
+	EnumType    string // Name of the enum type declared, for columns with enum values
 	Default     interface{}
 	Constraints []*Constraint
 	Indexes     []*Index
+	Unvalidated []string // Parts of 'check' not validated by xql, left to the database
 	table       interface{}
+	rules       []rule
+}
+
+// nullable
+// Which implemented by types carrying their own NULL state, like Field[T].
+type nullable interface {
+	isNull() bool
+}
+
+var nullableType = reflect.TypeOf((*nullable)(nil)).Elem()
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	}
+	return false
+}
+
+// isNullValue
+// Which tells if v holds NULL: a nil pointer or interface, or an invalid Field[T].
+func isNullValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	if v.CanInterface() {
+		if n, ok := v.Interface().(nullable); ok {
+			return n.isNull()
+		}
+	}
+	return false
+}
+
+// Omitted
+// Which tells if the column should be left out when writing the given value
+// without an explicit column list, generated columns are always omitted.
+// On insert a value holding NULL (nil pointer, invalid Field[T]) is written as
+// NULL to a nullable column without default, and omitted otherwise. The
+// 'softdelete' column is only cleared by Restore. On update NULL means not set,
+// and so does an empty 'autocreate' column. Other empty values are omitted, so
+// the column is left unset. Columns tagged with 'always' (or 'omitempty=false')
+// are always written.
+func (c *Column) Omitted(v reflect.Value, insert bool) bool {
+	if !v.IsValid() || c.Generated {
+		return true
+	}
+	if !insert && c.AutoCreate && (isNullValue(v) || v.IsZero()) {
+		return true
+	}
+	if c.Always {
+		return false
+	}
+	if isNullValue(v) {
+		return !insert || !c.Nullable || nil != c.Default || c.SoftDelete
+	}
+	return isEmptyValue(v)
 }
 
 type Declarable interface {
 	Declare(props PropertySet) string
 }
 
+// TryDeclarable
+// Which implemented by column types failing to declare for some properties or type
+// parameters, like postgres.Range[T] of T without a range type. The error is returned
+// by TryDeclareTable instead of a column type.
+type TryDeclarable interface {
+	TryDeclare(props PropertySet) (string, error)
+}
+
+// Generated
+// Which column type implemented tells if the column value is generated by database.
+type Generated interface {
+	Generated(props PropertySet) bool
+}
+
+// DefaultDeclare
+// Which declares the column type of field f common to all dialects, given by tag 'type'
+// or inferred from the type of f.
 func DefaultDeclare(f reflect.StructField, props PropertySet) (string, error) {
+	return DeclareFor("", f, props)
+}
+
+// DeclareFor
+// Which declares the column type of field f for the dialect named, like DefaultDeclare
+// but prefers the types registered for the dialect by RegisterDialectType.
+func DeclareFor(dialect string, f reflect.StructField, props PropertySet) (string, error) {
 	if t, ok := props.GetString("type"); ok {
 		t = strings.ToLower(t)
 		switch t {
@@ -60,20 +157,36 @@ func DefaultDeclare(f reflect.StructField, props PropertySet) (string, error) {
 			return Time(time.Time{}).Declare(props), nil
 		case "datetime", "timestamp":
 			return TimeStamp(time.Time{}).Declare(props), nil
-		case "decimal", "numeric":
+		case "decimal":
 			return Decimal("").Declare(props), nil
+		case "numeric":
+			return Numeric("").Declare(props), nil
 		case "uuid":
 			return UUID("").Declare(props), nil
 		default:
 			return t, nil
 		}
 	}
+	if d, ok := lookupType(dialect, f.Type); ok {
+		return d(props), nil
+	}
 	switch f.Type.Kind() {
+	case reflect.Ptr:
+		et := f.Type.Elem()
+		if d, ok := reflect.Zero(et).Interface().(Declarable); ok {
+			return d.Declare(props), nil
+		}
+		f.Type = et
+		return DeclareFor(dialect, f, props)
+	case reflect.Slice:
+		if f.Type.Elem().Kind() == reflect.Uint8 {
+			return Bytea(nil).Declare(props), nil
+		}
 	case reflect.String:
 		//size, _ := props.GetUInt("size", 32)
 		//return fmt.Sprintf("VARCHAR(%d)", size), nil
 		return Varchar("").Declare(props), nil
-	case reflect.Int16, reflect.Uint16:
+	case reflect.Int8, reflect.Uint8, reflect.Int16, reflect.Uint16:
 		//return "SMALLINT", nil
 		return SmallInteger(0).Declare(props), nil
 	case reflect.Int, reflect.Int32, reflect.Uint, reflect.Uint32:
@@ -89,10 +202,33 @@ func DefaultDeclare(f reflect.StructField, props PropertySet) (string, error) {
 		return Real(0.0).Declare(props), nil
 	case reflect.Float64:
 		return Double(0.0).Declare(props), nil
-	default:
-		return "", errors.New("Unknown type of:>" + f.Name)
 	}
+	return "", errors.New("Unknown type of:>" + f.Name + " (" + f.Type.String() + "), declare it with tag 'type' or RegisterType")
+}
 
+// TypeFor
+// Which returns the column type declared for the dialect named, which differs from
+// TypeDefine only if the dialect registered its own declaration of the column type
+// with RegisterDialectType. Enums and types declared by tag 'type' or by Declarable
+// are the same for all dialects.
+func (c *Column) TypeFor(dialect string) string {
+	if c.EnumType != "" {
+		return c.TypeDefine
+	}
+	v := reflect.Zero(c.Type)
+	if c.Type.Kind() == reflect.Ptr {
+		v = reflect.Zero(c.Type.Elem())
+	}
+	if d, ok := v.Interface().(dialectDeclarable); ok {
+		return d.declareFor(dialect, c.PropertySet)
+	}
+	if _, ok := v.Interface().(Declarable); ok {
+		return c.TypeDefine
+	}
+	if s, e := DeclareFor(dialect, reflect.StructField{Name: c.ElemName, Type: c.Type}, c.PropertySet); nil == e {
+		return s
+	}
+	return c.TypeDefine
 }
 
 // makeColumn
@@ -118,12 +254,27 @@ func makeColumn(t *Table, f reflect.StructField, v reflect.Value) *Column {
 	if fn, ok := props.PopString("name"); ok {
 		field.FieldName = fn
 	}
+	// index=true or index=<type>, like index=gin
+	indexType := IndexBTree
+	if it, ok := props.GetString("index"); ok {
+		if tp, ok := parseIndexType(it); ok {
+			indexType = tp
+			props["index"] = "t"
+		}
+	}
 	field.Indexed, _ = props.PopBool("index", false)
 	if field.Indexed {
 		field.Indexes = append(field.Indexes,
-			makeIndexes(IndexBTree, t.BaseTableName()+"_"+field.FieldName, field)...)
+			makeIndexes(indexType, t.BaseTableName()+"_"+field.FieldName, field)...)
+		// opclass=jsonb_path_ops
+		if class, ok := props.PopString("opclass"); ok {
+			for _, idx := range field.Indexes {
+				idx.Class = class
+			}
+		}
 	}
-	field.Nullable, _ = props.PopBool("nullable", false)
+	// Pointers and types carrying their own NULL state, like Field[T], are nullable by default.
+	field.Nullable, _ = props.PopBool("nullable", isNullType(f.Type))
 	if field.Nullable == false {
 		field.Constraints = append(field.Constraints,
 			makeConstraints(ConstraintNotNull, field)...)
@@ -141,6 +292,29 @@ func makeColumn(t *Table, f reflect.StructField, v reflect.Value) *Column {
 		field.Constraints = append(field.Constraints,
 			makeConstraints(ConstraintPrimaryKey, field)...)
 	}
+	if omitEmpty, ok := props.PopBool("omitempty", true); ok {
+		field.Always = !omitEmpty
+	}
+	if always, ok := props.PopBool("always", false); ok {
+		field.Always = always
+	}
+	field.SoftDelete, _ = props.PopBool("softdelete", false)
+	if field.SoftDelete && !field.Nullable {
+		panic("Column with 'softdelete' must be nullable: " + f.Name)
+	}
+	field.AutoCreate, _ = props.PopBool("autocreate", false)
+	field.AutoUpdate, _ = props.PopBool("autoupdate", false)
+	if (field.AutoCreate || field.AutoUpdate || field.SoftDelete) && !isTimeType(f.Type) {
+		panic("Column with 'autocreate', 'autoupdate' or 'softdelete' must be a time: " + f.Name)
+	}
+	field.Version, _ = props.PopBool("version", false)
+	if field.Version {
+		switch f.Type.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		default:
+			panic("Column with 'version' must be an integer: " + f.Name)
+		}
+	}
 	if fk, ok := props.GetString("foreignkey"); ok && fk != "" {
 		field.Constraints = append(field.Constraints,
 			makeConstraints(ConstraintForeignKey, field)...)
@@ -160,7 +334,23 @@ func makeColumn(t *Table, f reflect.StructField, v reflect.Value) *Column {
 		field.Default = df
 	}
 	//field.PropertySet = props
-	if p, ok := v.Interface().(Declarable); ok {
+	if f.Type.Kind() == reflect.Ptr {
+		// Methods of the element type, which are not callable on nil pointers.
+		v = reflect.Zero(f.Type.Elem())
+	}
+	if g, ok := v.Interface().(Generated); ok {
+		field.Generated = g.Generated(props)
+	}
+	field.EnumType = makeEnumType(t, field, v)
+	if field.EnumType != "" {
+		field.TypeDefine = field.EnumType
+	} else if p, ok := v.Interface().(TryDeclarable); ok {
+		d, e := p.TryDeclare(props)
+		if nil != e {
+			panic(e)
+		}
+		field.TypeDefine = d
+	} else if p, ok := v.Interface().(Declarable); ok {
 		field.TypeDefine = p.Declare(props)
 	} else {
 		if d, e := DefaultDeclare(f, props); nil == e {
@@ -170,6 +360,7 @@ func makeColumn(t *Table, f reflect.StructField, v reflect.Value) *Column {
 			panic(e)
 		}
 	}
+	field.rules = makeRules(field)
 	return field
 }
 
@@ -199,7 +390,7 @@ func makeColumns(t *Table, p interface{}, recursive bool, skips ...string) []*Co
 			continue
 		}
 		xTags := strings.Split(f.Tag.Get("xql"), ",")
-		if xTags[0] == "-" {
+		if xTags[0] == "-" || isRelationField(f) {
 			continue
 		}
 		if f.Anonymous {
@@ -218,3 +409,24 @@ func makeColumns(t *Table, p interface{}, recursive bool, skips ...string) []*Co
 	}
 	return fields
 }
+
+// isTimeType
+// Which reports whether a field of type t can hold a timestamp filled by xql:
+// time.Time, *time.Time or a type time.Time is assignable to.
+func isTimeType(t reflect.Type) bool {
+	return (t.Kind() == reflect.Ptr && t.Elem() == timeType) || timeType.AssignableTo(t)
+}
+
+// setTime
+// Which set timestamp ts to field v.
+func setTime(v reflect.Value, ts time.Time) error {
+	switch {
+	case v.Kind() == reflect.Ptr && v.Type().Elem() == timeType:
+		v.Set(reflect.ValueOf(&ts))
+	case timeType.AssignableTo(v.Type()):
+		v.Set(reflect.ValueOf(ts))
+	default:
+		return errors.New("not a time field: " + v.Type().String())
+	}
+	return nil
+}
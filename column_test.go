@@ -0,0 +1,72 @@
+package xql
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsTimeType(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want bool
+	}{
+		{time.Time{}, true},
+		{&time.Time{}, true},
+		{(*interface{})(nil), true},
+		{sql.NullTime{}, false},
+		{Field[time.Time]{}, false},
+		{TimeStamp{}, false},
+		{"2024-01-01", false},
+		{int64(0), false},
+		{(**time.Time)(nil), false},
+	}
+	for _, c := range cases {
+		typ := reflect.TypeOf(c.v)
+		if _, ok := c.v.(*interface{}); ok {
+			typ = typ.Elem()
+		}
+		if got := isTimeType(typ); got != c.want {
+			t.Errorf("isTimeType(%s) = %v, want %v", typ, got, c.want)
+		}
+	}
+}
+
+func TestSetTime(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var x struct {
+		T time.Time
+		P *time.Time
+		I interface{}
+		S sql.NullTime
+	}
+	v := reflect.ValueOf(&x).Elem()
+	for i := 0; i < 3; i++ {
+		if err := setTime(v.Field(i), now); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if !x.T.Equal(now) || !x.P.Equal(now) || x.I != now {
+		t.Errorf("set = %v", x)
+	}
+	if err := setTime(v.Field(3), now); nil == err {
+		t.Error("expected error of sql.NullTime")
+	}
+}
+
+type badTimestamps struct {
+	Id      int          `xql:"type=serial,pk"`
+	Updated sql.NullTime `xql:"type=timestamp,autoupdate"`
+}
+
+func (b badTimestamps) TableName() string { return "bad_timestamps" }
+
+func TestDeclareTimeColumns(t *testing.T) {
+	defer func() {
+		if nil == recover() {
+			t.Error("expected panic of autoupdate on sql.NullTime")
+		}
+	}()
+	DeclareTable(badTimestamps{})
+}
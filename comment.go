@@ -0,0 +1,147 @@
+package xql
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TagPosition
+// Where the query tags comment is placed in a statement.
+type TagPosition uint8
+
+const (
+	TagSuffix TagPosition = iota
+	TagPrefix
+)
+
+// QueryTags
+// Key/value tags rendered as a sqlcommenter style comment, like:
+//
+//	SELECT ... /*caller='students.go%3A42',route='%2Fstudents'*/
+//
+// Keys are sorted so that the same tags always render the same statement text,
+// which keeps statement caches keyed by SQL text working. Tag values should be
+// static, like routes or callers: values varying on every request (like request
+// ids) make every statement distinct, see Session.LimitTag to bound them.
+type QueryTags map[string]string
+
+// tagLimiter
+// Which bounds the count of distinct values of tag keys, shared by copies of a session.
+type tagLimiter struct {
+	sync.Mutex
+	limits map[string]int
+	values map[string]map[string]struct{}
+}
+
+// accept
+// Which tells if the tag can be rendered, that is the key is not limited, the value
+// was seen before or the key has taken less values than its limit. A warning is
+// logged when a key reaches its limit.
+func (l *tagLimiter) accept(key, value string) bool {
+	l.Lock()
+	defer l.Unlock()
+	max, ok := l.limits[key]
+	if !ok {
+		return true
+	}
+	set := l.values[key]
+	if _, ok := set[value]; ok {
+		return true
+	}
+	if len(set) >= max {
+		return false
+	}
+	set[value] = struct{}{}
+	if len(set) == max {
+		log.Printf("xql: tag '%s' reached %d distinct values, new values are left out of statements", key, max)
+	}
+	return true
+}
+
+// filter
+// Which returns tags without those rejected by the limits.
+func (l *tagLimiter) filter(tags QueryTags) QueryTags {
+	var rejected []string
+	for k, v := range tags {
+		if !l.accept(k, v) {
+			rejected = append(rejected, k)
+		}
+	}
+	if len(rejected) < 1 {
+		return tags
+	}
+	kept := make(QueryTags, len(tags))
+	for k, v := range tags {
+		kept[k] = v
+	}
+	for _, k := range rejected {
+		delete(kept, k)
+	}
+	return kept
+}
+
+func (tags QueryTags) merge(others QueryTags) QueryTags {
+	if len(others) < 1 {
+		return tags
+	}
+	merged := make(QueryTags, len(tags)+len(others))
+	for k, v := range tags {
+		merged[k] = v
+	}
+	for k, v := range others {
+		merged[k] = v
+	}
+	return merged
+}
+
+func sqlCommentEscape(s string) string {
+	s = strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+	return strings.Replace(s, "'", "\\'", -1)
+}
+
+// String
+// Which renders tags as a sqlcommenter comment, empty string if no tags.
+func (tags QueryTags) String() string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	if len(keys) < 1 {
+		return ""
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s='%s'", sqlCommentEscape(k), sqlCommentEscape(tags[k])))
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// Annotate
+// Which returns the statement with tags comment placed at position.
+func (tags QueryTags) Annotate(s string, position TagPosition) string {
+	c := tags.String()
+	if c == "" {
+		return s
+	}
+	if position == TagPrefix {
+		return c + " " + s
+	}
+	return strings.TrimRight(s, "; \n") + " " + c
+}
+
+// callerTag
+// Which returns 'file:line' of the caller skip frames above.
+func callerTag(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
@@ -0,0 +1,75 @@
+package xql
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestQueryTagsString(t *testing.T) {
+	cases := []struct {
+		name string
+		tags QueryTags
+		want string
+	}{
+		{"empty", nil, ""},
+		{"sorted", QueryTags{"route": "/a", "action": "list", "caller": "a.go:1"},
+			`/*action='list',caller='a.go%3A1',route='%2Fa'*/`},
+		{"spaces and quotes", QueryTags{"app name": "it's"},
+			`/*app%20name='it%27s'*/`},
+		{"comment end", QueryTags{"x": "*/ DROP TABLE t; /*"},
+			`/*x='%2A%2F%20DROP%20TABLE%20t%3B%20%2F%2A'*/`},
+		{"unicode", QueryTags{"route": "/学生"},
+			`/*route='%2F%E5%AD%A6%E7%94%9F'*/`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if s := c.tags.String(); s != c.want {
+				t.Errorf("got  %s\nwant %s", s, c.want)
+			}
+		})
+	}
+}
+
+func TestQueryTagsAnnotate(t *testing.T) {
+	tags := QueryTags{"route": "r"}
+	if s := tags.Annotate("SELECT 1; \n", TagSuffix); s != "SELECT 1 /*route='r'*/" {
+		t.Errorf("suffix = %q", s)
+	}
+	if s := tags.Annotate("SELECT 1", TagPrefix); s != "/*route='r'*/ SELECT 1" {
+		t.Errorf("prefix = %q", s)
+	}
+	if s := QueryTags(nil).Annotate("SELECT 1;", TagSuffix); s != "SELECT 1;" {
+		t.Errorf("no tags = %q", s)
+	}
+}
+
+func TestQueryTagsMerge(t *testing.T) {
+	a := QueryTags{"a": "1", "b": "1"}
+	m := a.merge(QueryTags{"b": "2"})
+	if m["a"] != "1" || m["b"] != "2" || a["b"] != "1" {
+		t.Errorf("merge = %v, original = %v", m, a)
+	}
+}
+
+func TestLimitTag(t *testing.T) {
+	session := &Session{}
+	session.LimitTag("request_id", 3)
+	for i := 0; i < 3; i++ {
+		if s := session.Tag("request_id", fmt.Sprint(i)).annotate("SELECT 1", nil); s == "SELECT 1" {
+			t.Fatalf("tag %d rejected", i)
+		}
+	}
+	tagged := session.Tag("route", "r")
+	if s := tagged.annotate("SELECT 1", QueryTags{"request_id": "3"}); s != "SELECT 1 /*route='r'*/" {
+		t.Errorf("got %s", s)
+	}
+	if s := tagged.annotate("SELECT 1", QueryTags{"request_id": "1"}); s != "SELECT 1 /*request_id='1',route='r'*/" {
+		t.Errorf("known value rejected: %s", s)
+	}
+	// Other keys and sessions are not limited.
+	for i := 0; i < 5; i++ {
+		if s := (&Session{}).Tag("request_id", fmt.Sprint(i)).annotate("SELECT 1", nil); s == "SELECT 1" {
+			t.Fatalf("tag %d rejected without limit", i)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package xql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+var decimalRex = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// declareNumeric
+// Which declares name(p,s) with tag 'precision' and 'scale', or name only without precision.
+func declareNumeric(name string, props PropertySet) string {
+	p, ok := props.GetUInt("precision")
+	if !ok || p < 1 {
+		return name
+	}
+	if s, ok := props.GetUInt("scale"); ok {
+		return fmt.Sprintf("%s(%d,%d)", name, p, s)
+	}
+	return fmt.Sprintf("%s(%d)", name, p)
+}
+
+// ParseDecimal
+// Which returns the Decimal of s, an error if s is not a decimal number.
+func ParseDecimal(s string) (Decimal, error) {
+	if !decimalRex.MatchString(s) {
+		return "", errors.New("invalid decimal: " + s)
+	}
+	return Decimal(s), nil
+}
+
+// DecimalFromInt
+// Which returns the Decimal of integer i.
+func DecimalFromInt(i int64) Decimal {
+	return Decimal(strconv.FormatInt(i, 10))
+}
+
+// DecimalFromRat
+// Which returns the Decimal of r with scale digits after the decimal point, the
+// last digit is rounded half away from zero.
+func DecimalFromRat(r *big.Rat, scale int) Decimal {
+	return Decimal(r.FloatString(scale))
+}
+
+// String
+// Which returns the Decimal as is.
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Rat
+// Which returns the exact value of Decimal.
+func (d Decimal) Rat() (*big.Rat, error) {
+	if !decimalRex.MatchString(string(d)) {
+		return nil, errors.New("invalid decimal: " + string(d))
+	}
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return nil, errors.New("invalid decimal: " + string(d))
+	}
+	return r, nil
+}
+
+// Int64
+// Which returns the Decimal as int64, an error if it is not an integer or out of range.
+func (d Decimal) Int64() (int64, error) {
+	r, e := d.Rat()
+	if nil != e {
+		return 0, e
+	}
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, errors.New("not an int64: " + string(d))
+	}
+	return r.Num().Int64(), nil
+}
+
+// Round
+// Which returns the Decimal rounded to scale digits after the decimal point.
+func (d Decimal) Round(scale int) (Decimal, error) {
+	r, e := d.Rat()
+	if nil != e {
+		return "", e
+	}
+	return DecimalFromRat(r, scale), nil
+}
+
+// Scan implements the sql.Scanner interface, values are kept as the text of database.
+func (d *Decimal) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		*d = Decimal(v)
+	case string:
+		*d = Decimal(v)
+	case int64:
+		*d = DecimalFromInt(v)
+	case nil:
+		return errors.New("converting NULL to Decimal is unsupported, use Field[Decimal] or *Decimal")
+	default:
+		return fmt.Errorf("converting %T to Decimal is unsupported", value)
+	}
+	return nil
+}
+
+// Value implements the driver Valuer interface, an empty Decimal is written as NULL.
+func (d Decimal) Value() (driver.Value, error) {
+	if d == "" {
+		return nil, nil
+	}
+	if !decimalRex.MatchString(string(d)) {
+		return nil, errors.New("invalid decimal: " + string(d))
+	}
+	return string(d), nil
+}
+
+func (n Numeric) String() string {
+	return string(n)
+}
+
+// Rat
+// Which returns the exact value of Numeric.
+func (n Numeric) Rat() (*big.Rat, error) {
+	return Decimal(n).Rat()
+}
+
+// Scan implements the sql.Scanner interface, see Decimal.Scan.
+func (n *Numeric) Scan(value interface{}) error {
+	return (*Decimal)(n).Scan(value)
+}
+
+// Value implements the driver Valuer interface, see Decimal.Value.
+func (n Numeric) Value() (driver.Value, error) {
+	return Decimal(n).Value()
+}
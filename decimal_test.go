@@ -0,0 +1,132 @@
+package xql_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+type Invoice struct {
+	Id     int         `xql:"type=serial,pk"`
+	Total  xql.Decimal `xql:"precision=12,scale=2"`
+	Rate   xql.Numeric `xql:"precision=5"`
+	Amount xql.Numeric
+	Tax    xql.Field[xql.Decimal] `xql:"precision=10,scale=4"`
+}
+
+func (i Invoice) TableName() string { return "invoices" }
+
+func TestDecimalDeclare(t *testing.T) {
+	table := xql.DeclareTable(Invoice{})
+	for name, want := range map[string]string{
+		"total":  "decimal(12,2)",
+		"rate":   "numeric(5)",
+		"amount": "numeric",
+		"tax":    "decimal(10,4)",
+	} {
+		if c, _ := table.GetColumn(name); c.TypeDefine != want {
+			t.Errorf("%s declared %q, want %q", name, c.TypeDefine, want)
+		}
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	for _, s := range []string{"0", "-1", "+1.50", "123456789012345678901234567890.000000001", ".5", "5.", "1e-3", "-2.5E+10"} {
+		if d, err := xql.ParseDecimal(s); nil != err || string(d) != s {
+			t.Errorf("ParseDecimal(%s) = %s, %v", s, d, err)
+		}
+	}
+	for _, s := range []string{"", ".", "1.2.3", "1,5", "NaN", "Infinity", "0x10", "1e", " 1"} {
+		if _, err := xql.ParseDecimal(s); nil == err {
+			t.Errorf("ParseDecimal(%q) should fail", s)
+		}
+	}
+}
+
+func TestDecimalConversions(t *testing.T) {
+	a, _ := xql.Decimal("0.1").Rat()
+	b, _ := xql.Decimal("0.2").Rat()
+	if sum := xql.DecimalFromRat(new(big.Rat).Add(a, b), 2); sum != "0.30" {
+		t.Errorf("0.1 + 0.2 = %s", sum)
+	}
+	big1, _ := xql.Decimal("123456789012345678901234567890.123456789").Rat()
+	if s := xql.DecimalFromRat(big1, 9); s != "123456789012345678901234567890.123456789" {
+		t.Errorf("exact = %s", s)
+	}
+	rounds := []struct {
+		d     xql.Decimal
+		scale int
+		want  xql.Decimal
+	}{
+		{"2.345", 2, "2.35"},
+		{"-2.345", 2, "-2.35"},
+		{"2.344", 2, "2.34"},
+		{"1e3", 0, "1000"},
+		{"7", 2, "7.00"},
+	}
+	for _, c := range rounds {
+		if r, err := c.d.Round(c.scale); nil != err || r != c.want {
+			t.Errorf("Round(%s, %d) = %s, %v, want %s", c.d, c.scale, r, err, c.want)
+		}
+	}
+	if d := xql.DecimalFromInt(-9007199254740993); d != "-9007199254740993" {
+		t.Errorf("DecimalFromInt = %s", d)
+	}
+	if n, err := xql.Decimal("9007199254740993.0").Int64(); nil != err || n != 9007199254740993 {
+		t.Errorf("Int64 = %d, %v", n, err)
+	}
+	for _, d := range []xql.Decimal{"1.5", "99999999999999999999", "x"} {
+		if _, err := d.Int64(); nil == err {
+			t.Errorf("Int64(%s) should fail", d)
+		}
+	}
+	if _, err := xql.Decimal("abc").Rat(); nil == err {
+		t.Error("Rat of invalid decimal should fail")
+	}
+}
+
+func TestDecimalScanValue(t *testing.T) {
+	var d xql.Decimal
+	for src, want := range map[interface{}]xql.Decimal{
+		"12.3400":  "12.3400",
+		int64(-42): "-42",
+	} {
+		if err := d.Scan(src); nil != err || d != want {
+			t.Errorf("Scan(%v) = %s, %v, want %s", src, d, err, want)
+		}
+	}
+	if err := d.Scan([]byte("0.000000000000000000001")); nil != err || d != "0.000000000000000000001" {
+		t.Errorf("Scan([]byte) = %s, %v", d, err)
+	}
+	if err := d.Scan(nil); nil == err {
+		t.Error("Scan(NULL) should fail")
+	}
+	if err := d.Scan(1.5); nil == err {
+		t.Error("Scan(float64) should fail, floats are not exact")
+	}
+	if v, err := xql.Decimal("1.10").Value(); nil != err || v != "1.10" {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+	if v, err := xql.Decimal("").Value(); nil != err || nil != v {
+		t.Errorf("empty Value() = %v, %v, want NULL", v, err)
+	}
+	if _, err := xql.Decimal("1,5").Value(); nil == err {
+		t.Error("Value() of invalid decimal should fail")
+	}
+
+	var n xql.Numeric
+	if err := n.Scan([]byte("3.14159")); nil != err || n != "3.14159" {
+		t.Errorf("Numeric Scan = %s, %v", n, err)
+	}
+	if v, _ := n.Value(); v != "3.14159" {
+		t.Errorf("Numeric Value() = %v", v)
+	}
+	var f xql.Field[xql.Decimal]
+	if err := f.Scan(nil); nil != err || f.Valid {
+		t.Errorf("Field Scan(NULL) = %+v, %v", f, err)
+	}
+	if err := f.Scan([]byte("5.00")); nil != err || !f.Valid || f.V != "5.00" {
+		t.Errorf("Field Scan = %+v, %v", f, err)
+	}
+}
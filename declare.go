@@ -0,0 +1,93 @@
+package xql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TypeDeclare
+// Which declares the column type of a Go type, with properties of tag.
+type TypeDeclare func(props PropertySet) string
+
+// dialectDeclarable
+// Which implemented by types declaring their column type with the registry of a dialect,
+// like Field[T].
+type dialectDeclarable interface {
+	declareFor(dialect string, props PropertySet) string
+}
+
+// typeKey
+// The key of a declaration registered, dialect is empty for declarations of all dialects.
+type typeKey struct {
+	dialect string
+	t       reflect.Type
+}
+
+var typeDeclares sync.Map
+
+// RegisterType
+// Which registers the column declaration of the type of sample for all dialects, used
+// by DefaultDeclare when no 'type' is given in tag. Applications can register mappings
+// of their own types:
+//
+//	xql.RegisterType(Money{}, func(props xql.PropertySet) string { return "numeric(18,2)" })
+//
+// Struct types are not inferred, as there is no single column type for them: a struct
+// field neither registered nor Declarable fails the declaration of its table, unless
+// declared with tag 'type'. Register structs stored as json, for example:
+//
+//	xql.RegisterType(Address{}, func(props xql.PropertySet) string { return "jsonb" })
+func RegisterType(sample interface{}, declare TypeDeclare) {
+	RegisterDialectType("", sample, declare)
+}
+
+// RegisterDialectType
+// Which registers the column declaration of the type of sample for the dialect named only,
+// preferred to the one of RegisterType by Column.TypeFor and DeclareFor of the dialect.
+// Dialect packages register their own mappings on init:
+//
+//	xql.RegisterDialectType("postgres", json.RawMessage{}, func(props xql.PropertySet) string { return "jsonb" })
+func RegisterDialectType(dialect string, sample interface{}, declare TypeDeclare) {
+	typeDeclares.Store(typeKey{dialect, reflect.TypeOf(sample)}, declare)
+}
+
+// lookupType
+// Which returns the declaration of type t registered for dialect, or for all dialects.
+func lookupType(dialect string, t reflect.Type) (TypeDeclare, bool) {
+	if dialect != "" {
+		if d, ok := typeDeclares.Load(typeKey{dialect, t}); ok {
+			return d.(TypeDeclare), true
+		}
+	}
+	if d, ok := typeDeclares.Load(typeKey{"", t}); ok {
+		return d.(TypeDeclare), true
+	}
+	return nil, false
+}
+
+// isNullType
+// Which tells if a column of type t is nullable by default: pointers, sql.Null* types
+// and types carrying their own NULL state, like Field[T].
+func isNullType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Implements(nullableType) {
+		return true
+	}
+	return t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null")
+}
+
+func init() {
+	RegisterType(time.Time{}, TimeStamp(time.Time{}).Declare)
+	RegisterType(json.RawMessage{}, func(props PropertySet) string { return "json" })
+	RegisterType(sql.NullString{}, Varchar("").Declare)
+	RegisterType(sql.NullInt64{}, BigInteger(0).Declare)
+	RegisterType(sql.NullInt32{}, Integer(0).Declare)
+	RegisterType(sql.NullInt16{}, SmallInteger(0).Declare)
+	RegisterType(sql.NullByte{}, SmallInteger(0).Declare)
+	RegisterType(sql.NullFloat64{}, Double(0).Declare)
+	RegisterType(sql.NullBool{}, Boolean(false).Declare)
+	RegisterType(sql.NullTime{}, TimeStamp(time.Time{}).Declare)
+}
@@ -0,0 +1,73 @@
+package xql
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type declared struct {
+	Id    int             `xql:"type=serial,pk"`
+	Data  json.RawMessage `xql:"nullable=true"`
+	Extra *json.RawMessage
+	Opt   Field[json.RawMessage]
+	Typed json.RawMessage `xql:"type=text"`
+}
+
+func (d declared) TableName() string { return "declared" }
+
+func TestTypeFor(t *testing.T) {
+	RegisterDialectType("declare_test", json.RawMessage{}, func(props PropertySet) string { return "document" })
+	table := DeclareTable(declared{})
+	cases := []struct {
+		column  string
+		common  string
+		dialect string
+	}{
+		{"data", "json", "document"},
+		{"extra", "json", "document"},
+		{"opt", "json", "document"},
+		{"typed", "text", "text"},
+	}
+	for _, c := range cases {
+		col, ok := table.GetColumn(c.column)
+		if !ok {
+			t.Fatalf("column %s not declared", c.column)
+		}
+		if col.TypeDefine != c.common {
+			t.Errorf("%s: TypeDefine = %q, want %q", c.column, col.TypeDefine, c.common)
+		}
+		if s := col.TypeFor("declare_test"); s != c.dialect {
+			t.Errorf("%s: TypeFor(declare_test) = %q, want %q", c.column, s, c.dialect)
+		}
+		if s := col.TypeFor("other"); s != c.common {
+			t.Errorf("%s: TypeFor(other) = %q, want %q", c.column, s, c.common)
+		}
+	}
+}
+
+type point struct {
+	X, Y int
+}
+
+type located struct {
+	Id int `xql:"type=serial,pk"`
+	At point
+}
+
+func (l located) TableName() string { return "located" }
+
+func TestDeclareStruct(t *testing.T) {
+	_, err := TryDeclareTable(located{})
+	if nil == err || !strings.Contains(err.Error(), "xql.point") || !strings.Contains(err.Error(), "RegisterType") {
+		t.Fatalf("unregistered struct error = %v", err)
+	}
+	RegisterDialectType("declare_test", point{}, func(props PropertySet) string { return "jsonb" })
+	if _, err := TryDeclareTable(located{}); nil == err {
+		t.Error("struct registered for another dialect only declared")
+	}
+	if s, err := DeclareFor("declare_test", reflect.StructField{Name: "At", Type: reflect.TypeOf(point{})}, PropertySet{}); nil != err || s != "jsonb" {
+		t.Errorf("DeclareFor() = %q, %v", s, err)
+	}
+}
@@ -14,6 +14,20 @@ type IDialect interface {
 	Delete(*Table, []QueryFilter) (string, []interface{}, error)
 }
 
+// IDialectInsertSelect
+// Which dialect implemented supports INSERT INTO ... SELECT ... statements, the
+// arguments after the target columns are those of Select on the source table.
+type IDialectInsertSelect interface {
+	InsertSelect(*Table, []string, *Table, []QueryColumn, []QueryFilter, []QueryOrder, string, int64, int64, *QueryConflict) (string, []interface{}, error)
+}
+
+// IDialectLocker
+// Which dialect implemented supports typed row-locking options. The returned string
+// is passed to Select as the lock clause, an error if the options can not be supported.
+type IDialectLocker interface {
+	LockFor(QueryLock) (string, error)
+}
+
 var builtinDialects map[string]IDialect
 
 func init() {
@@ -25,8 +39,9 @@ func RegisterDialect(name string, d IDialect) {
 }
 
 type Engine struct {
-	db         *sql.DB
-	driverName string
+	db           *sql.DB
+	driverName   string
+	interceptors []Interceptor
 }
 
 func CreateEngine(name string, dataSource string) (*Engine, error) {
@@ -47,7 +62,8 @@ func (engine Engine) DriverName() string {
 
 func (engine *Engine) MakeSession() *Session {
 	return &Session{
-		db:         engine.db,
-		driverName: engine.driverName,
+		db:           engine.db,
+		driverName:   engine.driverName,
+		interceptors: append([]Interceptor{}, engine.interceptors...),
 	}
 }
@@ -1,443 +1,433 @@
 package postgres
 
 import (
+	"database/sql"
 	"database/sql/driver"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"regexp"
+	"reflect"
+	"strconv"
 	"strings"
-
-	"github.com/lib/pq"
+	"time"
 
 	"github.com/archsh/go.xql"
 )
 
-type Elemented interface {
-	Elem2Strings() []string
-	Strings2Elem(...string) error
-}
-
-type StringArray []string
-
-func (h StringArray) Declare(props xql.PropertySet) string {
-	size, _ := props.GetInt("size", 32)
-	return fmt.Sprintf("varchar(%d)[]", size)
-}
-
+// Array
+// An array of T, declared from T like integer[] for Array[int] and character varying(32)[]
+// for Array[string] with tag 'size', or multidimensional of nested slices like integer[][]
+// for Array[[]int]. Elements of pointers or nullable types, like *string or xql.Field[int],
+// may be NULL. The element type can also be given by tag 'type', or 'enumtype' for an enum
+// type created by another column:
 //
-//func (a StringArray) Elem2Strings() []string {
-//	var ss []string
-//	for _, x := range a {
-//		ss = append(ss,
-//			strings.Replace(
-//				strings.Replace(x, `'`, `\'`, -1), `"`, `\"`, -1))
-//	}
-//	return ss
-//}
+//	Moods postgres.Array[string] `xql:"enumtype=mood"`
 //
-//func (a *StringArray) Strings2Elem(ss ...string) error {
-//	(*a) = ss
-//	return nil
-//}
-
-func (p *StringArray) Scan(src interface{}) error {
-	var ps []string
-	if e := pq.Array(&ps).Scan(src); nil != e {
-		return e
-	} else {
-		//fmt.Println(">>>", ps)
-		//fmt.Println("<<<", src)
-		*p = StringArray(ps)
-		//p = &pp
-		return nil
-	}
-	//return pq.Array(p).Scan(src)
-	//return Array_Scan(src, p)
+// A nil Array is written as NULL.
+type Array[T any] []T
+
+type StringArray = Array[string]
+type IntegerArray = Array[int]
+type SmallIntegerArray = Array[int16]
+type BigIntegerArray = Array[int64]
+type RealArray = Array[float32]
+type DoubleArray = Array[float64]
+type BoolArray = Array[bool]
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// isDimension
+// Which tells if t is a nested dimension of an array, slices other than []byte.
+func isDimension(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
 }
 
-func (p StringArray) Value() (driver.Value, error) {
-	return pq.Array([]string(p)).Value()
-	//return Array_Value(&p)
+func (a Array[T]) Declare(props xql.PropertySet) string {
+	s, _ := a.TryDeclare(props)
+	return s
 }
 
-type IntegerArray []int
-
-func (h IntegerArray) Declare(props xql.PropertySet) string {
-	return "integer[]"
-}
-
-//func (a IntegerArray) Elem2Strings() []string {
-//	ss := []string{}
-//	for _, x := range a {
-//		ss = append(ss, fmt.Sprintf("%d", x))
-//	}
-//	return ss
-//}
-//
-//func (a *IntegerArray) Strings2Elem(ss ...string) error {
-//	for _, s := range ss {
-//		n, e := strconv.ParseInt(s, 10, 32)
-//		if nil != e {
-//			return e
-//		} else {
-//			(*a) = append(*a, int(n))
-//		}
-//	}
-//	return nil
-//}
-
-func (p *IntegerArray) Scan(src interface{}) error {
-	var ps []int
-	if e := pq.Array(&ps).Scan(src); nil != e {
-		return e
-	} else {
-		//p = IntegerArray(ps)
-		//fmt.Println(">>>", ps)
-		//fmt.Println("<<<", src)
-		*p = IntegerArray(ps)
-		//p = &pp
-		return nil
+// TryDeclare
+// Which returns the array type of T, an error if T can not be declared.
+func (a Array[T]) TryDeclare(props xql.PropertySet) (string, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	dims := "[]"
+	for isDimension(t) {
+		t = t.Elem()
+		dims += "[]"
 	}
-	//return Array_Scan(src, p)
-}
-
-func (p IntegerArray) Value() (driver.Value, error) {
-	return pq.Array([]int(p)).Value()
-	//return Array_Value(&p)
+	if s, ok := props.GetString("type"); ok && strings.HasSuffix(s, "]") {
+		return s, nil
+	} else if !ok {
+		if name, ok := props.GetString("enumtype"); ok && name != "" {
+			return name + dims, nil
+		}
+		z := t
+		if z.Kind() == reflect.Ptr {
+			z = z.Elem()
+		}
+		if d, ok := reflect.Zero(z).Interface().(xql.TryDeclarable); ok {
+			s, e := d.TryDeclare(props)
+			if nil != e {
+				return "", e
+			}
+			return s + dims, nil
+		}
+		if d, ok := reflect.Zero(z).Interface().(xql.Declarable); ok {
+			return d.Declare(props) + dims, nil
+		}
+	}
+	s, e := xql.DeclareFor("postgres", reflect.StructField{Name: "V", Type: t}, props)
+	if nil != e {
+		return "", e
+	}
+	return s + dims, nil
 }
 
-type SmallIntegerArray []int16
-
-func (h SmallIntegerArray) Declare(props xql.PropertySet) string {
-	return "smallint[]"
+// Value implements the driver Valuer interface.
+func (a Array[T]) Value() (driver.Value, error) {
+	if nil == a {
+		return nil, nil
+	}
+	var sb strings.Builder
+	if e := formatArray(&sb, reflect.ValueOf(a)); nil != e {
+		return nil, e
+	}
+	return sb.String(), nil
 }
 
-//func (a SmallIntegerArray) Elem2Strings() []string {
-//	ss := []string{}
-//	for _, x := range a {
-//		ss = append(ss, fmt.Sprintf("%d", x))
-//	}
-//	return ss
-//}
-//
-//func (a *SmallIntegerArray) Strings2Elem(ss ...string) error {
-//	for _, s := range ss {
-//		n, e := strconv.ParseInt(s, 10, 16)
-//		if nil != e {
-//			return e
-//		} else {
-//			(*a) = append(*a, int16(n))
-//		}
-//	}
-//	return nil
-//}
-
-func (p *SmallIntegerArray) Scan(src interface{}) error {
-	var ps []int16
-	if e := pq.Array(&ps).Scan(src); nil != e {
-		return e
-	} else {
-		//p = SmallIntegerArray(ps)
-		//fmt.Println(">>>", ps)
-		//fmt.Println("<<<", src)
-		*p = SmallIntegerArray(ps)
-		//p = &pp
+// Scan implements the sql.Scanner interface, NULL makes the Array nil.
+func (a *Array[T]) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*a = nil
 		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("converting %T to Array is unsupported", src)
+	}
+	root, err := parseArrayText(s)
+	if nil != err {
+		return err
 	}
-	//return Array_Scan(src, p)
+	var x Array[T]
+	if err := scanArray(root, reflect.ValueOf(&x).Elem()); nil != err {
+		return err
+	}
+	*a = x
+	return nil
 }
 
-func (p SmallIntegerArray) Value() (driver.Value, error) {
-	return pq.Array([]int16(p)).Value()
-	//return Array_Value(&p)
+func formatArray(sb *strings.Builder, v reflect.Value) error {
+	sb.WriteByte('{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		x := v.Index(i)
+		if isDimension(x.Type()) {
+			if e := formatArray(sb, x); nil != e {
+				return e
+			}
+			continue
+		}
+		s, ok, e := formatElem(x)
+		if nil != e {
+			return e
+		}
+		if !ok {
+			sb.WriteString("NULL")
+		} else {
+			sb.WriteString(quoteBound(s))
+		}
+	}
+	sb.WriteByte('}')
+	return nil
 }
 
-type BigIntegerArray []int64
-
-func (h BigIntegerArray) Declare(props xql.PropertySet) string {
-	return "bigint[]"
+// formatElem
+// Which returns the text of an array element, false if it is NULL.
+func formatElem(v reflect.Value) (string, bool, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		if vr, ok := v.Interface().(driver.Valuer); ok {
+			return formatValue(vr)
+		}
+		v = v.Elem()
+	}
+	if vr, ok := v.Interface().(driver.Valuer); ok {
+		return formatValue(vr)
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format("2006-01-02 15:04:05.999999Z07:00"), true, nil
+	case []byte:
+		return `\x` + hex.EncodeToString(x), true, nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return `\x` + hex.EncodeToString(v.Bytes()), true, nil
+	}
+	return fmt.Sprint(v.Interface()), true, nil
 }
 
-//func (a BigIntegerArray) Elem2Strings() []string {
-//	ss := []string{}
-//	for _, x := range a {
-//		ss = append(ss, fmt.Sprintf("%d", x))
-//	}
-//	return ss
-//}
-//
-//func (a *BigIntegerArray) Strings2Elem(ss ...string) error {
-//	for _, s := range ss {
-//		n, e := strconv.ParseInt(s, 10, 64)
-//		if nil != e {
-//			return e
-//		} else {
-//			(*a) = append(*a, n)
-//		}
-//	}
-//	return nil
-//}
-
-func (p *BigIntegerArray) Scan(src interface{}) error {
-	var ps []int64
-	if e := pq.Array(&ps).Scan(src); nil != e {
-		return e
-	} else {
-		//p = BigIntegerArray(ps)
-		//fmt.Println(">>>", ps)
-		//fmt.Println("<<<", src)
-		*p = BigIntegerArray(ps)
-		//p = &pp
-		return nil
+func formatValue(vr driver.Valuer) (string, bool, error) {
+	x, e := vr.Value()
+	if nil != e || nil == x {
+		return "", false, e
 	}
-	//return Array_Scan(src, p)
+	if bs, ok := x.([]byte); ok {
+		// Text of types like UUID and JSON, declare bytea elements as []byte.
+		return string(bs), true, nil
+	}
+	return formatElem(reflect.ValueOf(x))
 }
 
-func (p BigIntegerArray) Value() (driver.Value, error) {
-	return pq.Array([]int64(p)).Value()
-	//return Array_Value(&p)
+// arrayElem
+// An element parsed from text of an array, a sub array if array is true.
+type arrayElem struct {
+	text  string
+	null  bool
+	array bool
+	elems []arrayElem
 }
 
-type RealArray []float32
-
-func (h RealArray) Declare(props xql.PropertySet) string {
-	return "real[]"
+type arrayParser struct {
+	s string
+	i int
 }
 
-//func (a RealArray) Elem2Strings() []string {
-//	ss := []string{}
-//	for _, x := range a {
-//		ss = append(ss, fmt.Sprintf("%f", x))
-//	}
-//	return ss
-//}
-//
-//func (a *RealArray) Strings2Elem(ss ...string) error {
-//	for _, s := range ss {
-//		n, e := strconv.ParseFloat(s, 32)
-//		if nil != e {
-//			return e
-//		} else {
-//			(*a) = append(*a, float32(n))
-//		}
-//	}
-//	return nil
-//}
-
-func (p *RealArray) Scan(src interface{}) error {
-	var ps []float32
-	if e := pq.Array(&ps).Scan(src); nil != e {
-		return e
-	} else {
-		//p = RealArray(ps)
-		//fmt.Println(">>>", ps)
-		//fmt.Println("<<<", src)
-		*p = RealArray(ps)
-		//p = &pp
-		return nil
+// parseArrayText
+// Which parses text of an array like {{1,NULL},{"a b",3}}, with optional dimension
+// decoration like [0:1]={1,2}.
+func parseArrayText(s string) (arrayElem, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		if i := strings.Index(s, "="); i > 0 {
+			s = s[i+1:]
+		}
 	}
-	//return Array_Scan(src, p)
-}
-
-func (p RealArray) Value() (driver.Value, error) {
-	return pq.Array([]float32(p)).Value()
-	//return Array_Value(&p)
+	p := &arrayParser{s: s}
+	x, err := p.array()
+	if nil == err && p.peek() != 0 {
+		err = errors.New("invalid array: " + s)
+	}
+	return x, err
 }
 
-type DoubleArray []float64
-
-func (h DoubleArray) Declare(props xql.PropertySet) string {
-	return "real[]"
+// peek
+// Which returns the next byte after spaces, 0 at the end.
+func (p *arrayParser) peek() byte {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+	if p.i < len(p.s) {
+		return p.s[p.i]
+	}
+	return 0
 }
 
-//func (a DoubleArray) Elem2Strings() []string {
-//	ss := []string{}
-//	for _, x := range a {
-//		ss = append(ss, fmt.Sprintf("%f", x))
-//	}
-//	return ss
-//}
-//
-//func (a *DoubleArray) Strings2Elem(ss ...string) error {
-//	for _, s := range ss {
-//		n, e := strconv.ParseFloat(s, 64)
-//		if nil != e {
-//			return e
-//		} else {
-//			(*a) = append(*a, n)
-//		}
-//	}
-//	return nil
-//}
-
-func (p *DoubleArray) Scan(src interface{}) error {
-	var ps []float64
-	if e := pq.Array(&ps).Scan(src); nil != e {
-		return e
-	} else {
-		//p = DoubleArray(ps)
-		//fmt.Println(">>>", ps)
-		//fmt.Println("<<<", src)
-		*p = DoubleArray(ps)
-		//p = &pp
-		return nil
+func (p *arrayParser) array() (arrayElem, error) {
+	x := arrayElem{array: true}
+	if p.peek() != '{' {
+		return x, errors.New("invalid array: " + p.s)
+	}
+	p.i++
+	if p.peek() == '}' {
+		p.i++
+		return x, nil
+	}
+	for {
+		var e arrayElem
+		var err error
+		if p.peek() == '{' {
+			e, err = p.array()
+		} else {
+			e, err = p.scalar()
+		}
+		if nil != err {
+			return x, err
+		}
+		x.elems = append(x.elems, e)
+		switch p.peek() {
+		case ',':
+			p.i++
+		case '}':
+			p.i++
+			return x, nil
+		default:
+			return x, errors.New("invalid array: " + p.s)
+		}
 	}
-	//return Array_Scan(src, p)
 }
 
-func (p DoubleArray) Value() (driver.Value, error) {
-	return pq.Array([]float64(p)).Value()
-	//return Array_Value(&p)
+func (p *arrayParser) scalar() (arrayElem, error) {
+	if p.peek() == '"' {
+		var sb strings.Builder
+		for p.i++; p.i < len(p.s); p.i++ {
+			switch c := p.s[p.i]; c {
+			case '\\':
+				if p.i++; p.i < len(p.s) {
+					sb.WriteByte(p.s[p.i])
+				}
+			case '"':
+				p.i++
+				return arrayElem{text: sb.String()}, nil
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return arrayElem{}, errors.New("invalid array: " + p.s)
+	}
+	start := p.i
+	for p.i < len(p.s) && p.s[p.i] != ',' && p.s[p.i] != '}' {
+		p.i++
+	}
+	s := strings.TrimSpace(p.s[start:p.i])
+	if s == "" {
+		return arrayElem{}, errors.New("invalid array: " + p.s)
+	} else if strings.EqualFold(s, "NULL") {
+		return arrayElem{null: true}, nil
+	}
+	return arrayElem{text: s}, nil
 }
 
-type BoolArray []bool
-
-func (h BoolArray) Declare(props xql.PropertySet) string {
-	return "bool[]"
+// scanArray
+// Which stores the parsed array x into slice dst.
+func scanArray(x arrayElem, dst reflect.Value) error {
+	if !x.array {
+		return fmt.Errorf("converting %q to %s is unsupported", x.text, dst.Type())
+	}
+	out := reflect.MakeSlice(dst.Type(), len(x.elems), len(x.elems))
+	for i, e := range x.elems {
+		d := out.Index(i)
+		if isDimension(d.Type()) {
+			if !e.null {
+				if err := scanArray(e, d); nil != err {
+					return err
+				}
+			}
+			continue
+		}
+		if e.array {
+			return fmt.Errorf("converting sub array to %s is unsupported", d.Type())
+		}
+		if err := scanElem(e, d); nil != err {
+			return err
+		}
+	}
+	dst.Set(out)
+	return nil
 }
 
-//func (a BoolArray) Elem2Strings() []string {
-//	ss := []string{}
-//	for _, x := range a {
-//		ss = append(ss, fmt.Sprintf("%s", x))
-//	}
-//	return ss
-//}
-//
-//func (a *BoolArray) Strings2Elem(ss ...string) error {
-//	for _, s := range ss {
-//		switch strings.ToLower(s) {
-//		case "y", "yes", "t", "true", "ok":
-//			(*a) = append(*a, true)
-//		default:
-//			(*a) = append(*a, false)
-//		}
-//	}
-//	return nil
-//}
-
-func (p *BoolArray) Scan(src interface{}) error {
-	var ps []bool
-	if e := pq.Array(&ps).Scan(src); nil != e {
-		return e
-	} else {
-		//p = BoolArray(ps)
-		//fmt.Println(">>>", ps)
-		//fmt.Println("<<<", src)
-		*p = BoolArray(ps)
+// scanElem
+// Which stores the parsed element x into d.
+func scanElem(x arrayElem, d reflect.Value) error {
+	if x.null {
+		if d.Kind() == reflect.Ptr {
+			d.Set(reflect.Zero(d.Type()))
+			return nil
+		} else if d.Addr().Type().Implements(scannerType) {
+			return d.Addr().Interface().(sql.Scanner).Scan(nil)
+		}
+		return fmt.Errorf("converting NULL element to %s is unsupported, use a pointer or nullable element type", d.Type())
+	}
+	if d.Kind() == reflect.Ptr {
+		p := reflect.New(d.Type().Elem())
+		if err := scanElem(x, p.Elem()); nil != err {
+			return err
+		}
+		d.Set(p)
 		return nil
 	}
-	//return Array_Scan(src, p)
+	if s, ok := d.Addr().Interface().(sql.Scanner); ok {
+		return s.Scan([]byte(x.text))
+	}
+	if _, ok := d.Interface().(time.Time); ok {
+		return parseBound(x.text, d.Addr().Interface())
+	}
+	switch d.Kind() {
+	case reflect.String:
+		d.SetString(x.text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(x.text)
+		if nil != err {
+			return err
+		}
+		d.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(x.text, 10, d.Type().Bits())
+		if nil != err {
+			return err
+		}
+		d.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(x.text, 10, d.Type().Bits())
+		if nil != err {
+			return err
+		}
+		d.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(x.text, d.Type().Bits())
+		if nil != err {
+			return err
+		}
+		d.SetFloat(f)
+	case reflect.Slice:
+		bs, err := hex.DecodeString(strings.TrimPrefix(x.text, `\x`))
+		if nil != err {
+			return err
+		}
+		d.SetBytes(bs)
+	default:
+		return fmt.Errorf("converting array element to %s is unsupported", d.Type())
+	}
+	return nil
 }
 
-func (p BoolArray) Value() (driver.Value, error) {
-	return pq.Array([]bool(p)).Value()
-	//return Array_Value(&p)
+// ArrayContains
+// Which makes a filter of array column field containing all values: field @> values.
+func ArrayContains[T any](field string, values ...T) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "@>", Value: Array[T](values)}
 }
 
-// PARSING ARRAYS
-// SEE http://www.postgresql.org/docs/9.1/static/arrays.html#ARRAYS-IO
-// Arrays are output within {} and a delimiter, which is a comma for most
-// postgres types (; for box)
-//
-// Individual values are surrounded by quotes:
-// The array output routine will put double quotes around element values if
-// they are empty strings, contain curly braces, delimiter characters,
-// double quotes, backslashes, or white space, or match the word NULL.
-// Double quotes and backslashes embedded in element values will be
-// backslash-escaped. For numeric data types it is safe to assume that double
-// quotes will never appear, but for textual data types one should be prepared
-// to cope with either the presence or absence of quotes.
-
-// construct a regexp to extract values:
-var (
-	// unquoted array values must not contain: (" , \ { } whitespace NULL)
-	// and must be at least one char
-	unquotedChar  = `[^",\\{}\s(NULL)]`
-	unquotedValue = fmt.Sprintf("(%s)+", unquotedChar)
-
-	// quoted array values are surrounded by double quotes, can be any
-	// character except " or \, which must be backslash escaped:
-	quotedChar  = `[^"\\]|\\"|\\\\`
-	quotedValue = fmt.Sprintf("\"(%s)*\"", quotedChar)
-
-	// an array value may be either quoted or unquoted:
-	arrayValue = fmt.Sprintf("(?P<value>(%s|%s))", unquotedValue, quotedValue)
-
-	// Array values are separated with a comma IF there is more than one value:
-	arrayExp = regexp.MustCompile(fmt.Sprintf("((%s)(,)?)", arrayValue))
-
-	valueIndex int
-)
-
-// Find the index of the 'value' named expression
-func init() {
-	for i, subexp := range arrayExp.SubexpNames() {
-		if subexp == "value" {
-			valueIndex = i
-			break
-		}
-	}
+// ArrayContainedBy
+// Which makes a filter of array column field contained by values: field <@ values.
+func ArrayContainedBy[T any](field string, values ...T) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "<@", Value: Array[T](values)}
 }
 
-// Parse the output string from the array type.
-// Regex used: (((?P<value>(([^",\\{}\s(NULL)])+|"([^"\\]|\\"|\\\\)*")))(,)?)
-func parseArray(array string) []string {
-	results := make([]string, 0)
-	matches := arrayExp.FindAllStringSubmatch(array, -1)
-	for _, match := range matches {
-		s := match[valueIndex]
-		// the string _might_ be wrapped in quotes, so trim them:
-		s = strings.Trim(s, "\"")
-		results = append(results, s)
-	}
-	return results
+// ArrayOverlaps
+// Which makes a filter of array column field having any of values: field && values.
+func ArrayOverlaps[T any](field string, values ...T) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "&&", Value: Array[T](values)}
 }
 
-//func (p *StringArray) Scan(src interface{}) error {
-//    asBytes, ok := src.([]byte)
-//    if !ok {
-//        return error(errors.New("Scan source was not []bytes."))
-//    }
-//
-//    asString := string(asBytes)
-//    parsed := parseArray(asString)
-//    (*p) = StringArray(parsed)
-//
-//    return nil
-//}
-//
-//func (p StringArray) Value() (driver.Value, error) {
-//    var ss []string
-//    for _, s := range p {
-//        ss = append(ss, fmt.Sprintf(`"%s"`, s))
-//    }
-//    return strings.Join([]string{"{", strings.Join(ss, ","), "}"}, ""), nil
-//}
-
-func Array_Scan(src interface{}, dest interface{}) error {
-	if nil == src || dest == nil {
-		return nil
-	}
-	asBytes, ok := src.([]byte)
-	if !ok {
-		return error(errors.New("Scan source was not []bytes."))
-	}
+// ArrayAny
+// Which makes a filter of array column field having element v: v = ANY(field).
+func ArrayAny(field string, v interface{}) xql.QueryFilter {
+	return xql.QueryFilter{Field: fmt.Sprintf("ANY(%s)", escapePGkw(field)), Operator: "=", Value: v, Reversed: true}
+}
 
-	asString := string(asBytes)
-	parsed := parseArray(asString)
-	if vv, ok := dest.(Elemented); ok {
-		return vv.Strings2Elem(parsed...)
-	}
-	return errors.New("Elemented should be implemented.")
+// AnyOf
+// Which makes a filter of field equal to any of values, as one array parameter: field = ANY(values).
+func AnyOf[T any](field string, values ...T) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "=", Function: "ANY", Value: Array[T](values)}
 }
 
-func Array_Value(v interface{}) (driver.Value, error) {
-	if nil == v {
-		return nil, nil
-	}
-	if vv, ok := v.(Elemented); ok {
-		return strings.Join([]string{"{", strings.Join(vv.Elem2Strings(), ","), "}"}, ""), nil
+// ArrayLength
+// Which returns the expression of length of array column field in dimension dim, 1 by default:
+//
+//	session.Table(SchoolTable).Where(postgres.ArrayLength("tags"), 3, ">=")
+func ArrayLength(field string, dim ...int) string {
+	d := 1
+	if len(dim) > 0 {
+		d = dim[0]
 	}
-	return nil, errors.New("Elemented should be implemented.")
+	return fmt.Sprintf("array_length(%s, %d)", escapePGkw(field), d)
 }
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+func TestArrayRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		a    Array[string]
+		text string
+	}{
+		{"plain", Array[string]{"a", "b"}, `{"a","b"}`},
+		{"empty array", Array[string]{}, `{}`},
+		{"empty element", Array[string]{""}, `{""}`},
+		{"commas and braces", Array[string]{"a,b", "{c}", "(d)"}, `{"a,b","{c}","(d)"}`},
+		{"spaces", Array[string]{" a ", "b c"}, `{" a ","b c"}`},
+		{"quotes", Array[string]{`say "hi"`}, `{"say \"hi\""}`},
+		{"backslashes", Array[string]{`a\b`, `\`}, `{"a\\b","\\"}`},
+		{"null text", Array[string]{"NULL", "null"}, `{"NULL","null"}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := c.a.Value()
+			if nil != err {
+				t.Fatal(err)
+			}
+			if v != c.text {
+				t.Errorf("Value() = %v, want %s", v, c.text)
+			}
+			var a Array[string]
+			if err := a.Scan([]byte(c.text)); nil != err {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(a, c.a) {
+				t.Errorf("Scan(%s) = %#v, want %#v", c.text, a, c.a)
+			}
+		})
+	}
+}
+
+func TestArrayScan(t *testing.T) {
+	var s Array[string]
+	if err := s.Scan(`{a, b c ,"d",NULL}`); nil == err {
+		t.Errorf("Scan of NULL into string = %#v, want error", s)
+	}
+	if err := s.Scan(`{a, b c ,"d"}`); nil != err || !reflect.DeepEqual(s, Array[string]{"a", "b c", "d"}) {
+		t.Errorf("Scan unquoted = %#v, %v", s, err)
+	}
+	if err := s.Scan(nil); nil != err || nil != s {
+		t.Errorf("Scan(NULL) = %#v, %v", s, err)
+	}
+	if err := s.Scan(`{}`); nil != err || nil == s || len(s) != 0 {
+		t.Errorf("Scan({}) = %#v, %v, want empty", s, err)
+	}
+
+	var p Array[*string]
+	if err := p.Scan(`{x,NULL,"NULL"}`); nil != err {
+		t.Fatal(err)
+	}
+	if len(p) != 3 || *p[0] != "x" || nil != p[1] || *p[2] != "NULL" {
+		t.Errorf("Scan pointers = %#v", p)
+	}
+	if v, _ := p.Value(); v != `{"x",NULL,"NULL"}` {
+		t.Errorf("Value() = %v", v)
+	}
+
+	var f Array[xql.Field[int]]
+	if err := f.Scan(`{1,NULL}`); nil != err {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f, Array[xql.Field[int]]{xql.NewField(1), {}}) {
+		t.Errorf("Scan fields = %#v", f)
+	}
+
+	var n Array[int]
+	if err := n.Scan(`[0:2]={1,2,3}`); nil != err || !reflect.DeepEqual(n, Array[int]{1, 2, 3}) {
+		t.Errorf("Scan decorated = %#v, %v", n, err)
+	}
+	if v, _ := (Array[int])(nil).Value(); nil != v {
+		t.Errorf("nil Value() = %v, want NULL", v)
+	}
+}
+
+func TestArrayDimensions(t *testing.T) {
+	var m Array[[]int]
+	if err := m.Scan(`{{1,2},{3,4}}`); nil != err || !reflect.DeepEqual(m, Array[[]int]{{1, 2}, {3, 4}}) {
+		t.Errorf("Scan 2-D = %#v, %v", m, err)
+	}
+	if v, _ := m.Value(); v != `{{"1","2"},{"3","4"}}` {
+		t.Errorf("Value() = %v", v)
+	}
+	var one Array[int]
+	if err := one.Scan(`{{1,2},{3,4}}`); nil == err {
+		t.Errorf("Scan 2-D into 1-D = %#v, want error", one)
+	}
+	if err := m.Scan(`{1,2}`); nil == err {
+		t.Errorf("Scan 1-D into 2-D = %#v, want error", m)
+	}
+	for _, bad := range []string{`{1,2`, `1,2}`, `{1,,2}`, `{"a}`, `{1}x`, `{{1},2`} {
+		if err := one.Scan(bad); nil == err {
+			t.Errorf("Scan(%s) = %#v, want error", bad, one)
+		}
+	}
+}
+
+func TestArrayCompositeElements(t *testing.T) {
+	r := Array[Range[int]]{NewRange(1, 10), EmptyRange[int]()}
+	v, err := r.Value()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if v != `{"[\"1\",\"10\")","empty"}` {
+		t.Errorf("Value() = %v", v)
+	}
+	var rs Array[Range[int]]
+	if err := rs.Scan(`{"[1,10)",empty,"(,5]"}`); nil != err {
+		t.Fatal(err)
+	}
+	want := Array[Range[int]]{NewRange(1, 10), EmptyRange[int](), {LowerInf: true, Upper: 5, UpperInc: true, Valid: true}}
+	if !reflect.DeepEqual(rs, want) {
+		t.Errorf("Scan ranges = %+v, want %+v", rs, want)
+	}
+
+	j := Array[RawJSONB]{{V: json.RawMessage(`{"a":[1,2]}`)}, {}}
+	if v, _ := j.Value(); v != `{"{\"a\":[1,2]}",NULL}` {
+		t.Errorf("Value() = %v", v)
+	}
+	var js Array[JSONB[map[string][]int]]
+	if err := js.Scan(`{"{\"a\": [1, 2]}","{}"}`); nil != err {
+		t.Fatal(err)
+	}
+	if len(js) != 2 || !reflect.DeepEqual(js[0].V, map[string][]int{"a": {1, 2}}) || len(js[1].V) != 0 {
+		t.Errorf("Scan json = %+v", js)
+	}
+}
+
+type badSpans struct {
+	Id    int `xql:"type=serial,pk"`
+	Spans Array[Range[string]]
+}
+
+func (b badSpans) TableName() string { return "bad_spans" }
+
+func TestArrayDeclareError(t *testing.T) {
+	if s := (Array[Range[string]]{}).Declare(nil); s != "" {
+		t.Errorf("Declare() = %q, want empty", s)
+	}
+	if _, err := xql.TryDeclareTable(badSpans{}); nil == err || !strings.Contains(err.Error(), "range type of string") {
+		t.Errorf("TryDeclareTable() error = %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+type document struct {
+	Id   int             `xql:"type=serial,pk"`
+	Body json.RawMessage `xql:"nullable=true"`
+	Tags Array[json.RawMessage]
+}
+
+func (d document) TableName() string { return "documents" }
+
+func TestCreateJSONB(t *testing.T) {
+	table := xql.DeclareTable(document{})
+	s, _, err := postgresDialect{}.Create(table)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"body jsonb", "tags jsonb[]"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%s not declared in:\n%s", want, s)
+		}
+	}
+	// Other dialects keep the common declaration.
+	if c, _ := table.GetColumn("body"); c.TypeDefine != "json" {
+		t.Errorf("TypeDefine = %q, want json", c.TypeDefine)
+	}
+}
+
+type mood string
+
+func (m mood) EnumValues() []string { return []string{"sad", "it's ok", "happy"} }
+
+type diary struct {
+	Id    int    `xql:"type=serial,pk"`
+	Mood  mood   `xql:"enumtype=mood"`
+	Shelf string `xql:"enum=top;low,nullable=true"`
+}
+
+func (d diary) TableName() string { return "diaries" }
+
+func TestCreateEnum(t *testing.T) {
+	s, _, err := postgresDialect{}.Create(xql.DeclareTable(diary{}))
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"CREATE TYPE mood AS ENUM ('sad','it''s ok','happy');",
+		"CREATE TYPE diaries_shelf AS ENUM ('top','low');",
+		"mood mood NOT NULL",
+		"shelf diaries_shelf",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q not found in:\n%s", want, s)
+		}
+	}
+	if strings.Contains(s, "CHECK") {
+		t.Errorf("enum checked in:\n%s", s)
+	}
+	s, _, _ = postgresDialect{}.Create(xql.DeclareTable(diary{}, "journal"))
+	for _, want := range []string{
+		"CREATE TYPE mood AS ENUM",
+		"CREATE TYPE journal.diaries_shelf AS ENUM ('top','low');",
+		"shelf journal.diaries_shelf",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q not found in:\n%s", want, s)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+type member struct {
+	Id    int              `xql:"type=serial,pk"`
+	Name  string           `xql:"size=32"`
+	Nick  *string          `xql:"size=32"`
+	Score xql.Field[int64] `xql:"type=bigint"`
+	Note  *string          `xql:"type=text,default='none'"`
+}
+
+func (m member) TableName() string { return "members" }
+
+var memberTable = xql.DeclareTable(member{})
+
+func TestInsertOmitted(t *testing.T) {
+	nick := "neo"
+	cases := []struct {
+		name string
+		obj  member
+		cols []string
+		want string
+	}{
+		{"nulls written", member{},
+			nil, `INSERT INTO members (nick,score) VALUES($1,$2)`},
+		{"values", member{Name: "a", Nick: &nick, Score: xql.NewField(int64(0)), Note: &nick},
+			nil, `INSERT INTO members ("name",nick,score,note) VALUES($1,$2,$3,$4)`},
+		{"explicit", member{},
+			[]string{"name", "note"}, `INSERT INTO members ("name",note) VALUES($1,$2)`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, _, err := postgresDialect{}.Insert(memberTable, c.obj, c.cols...)
+			if nil != err {
+				t.Fatal(err)
+			}
+			if s != c.want {
+				t.Errorf("got  %s\nwant %s", s, c.want)
+			}
+			s, _, _ = postgresDialect{}.InsertWithInsertedId(memberTable, c.obj, "id", c.cols...)
+			if s != c.want+" RETURNING id" {
+				t.Errorf("got  %s\nwant %s RETURNING id", s, c.want)
+			}
+		})
+	}
+}
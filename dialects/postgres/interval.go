@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+// Interval
+// An interval in fields of PostgreSQL, months and days are kept apart from time
+// since their lengths vary. Scanned from text of all IntervalStyles, written in
+// ISO 8601 format.
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+// IntervalOf
+// Which makes an Interval of duration d, in microseconds only.
+func IntervalOf(d time.Duration) Interval {
+	return Interval{Microseconds: d.Microseconds()}
+}
+
+// ParseInterval
+// Which parses s like '1 year 2 mons 3 days 04:05:06.789', '@ 1 day 2 hours ago',
+// '1-2 3 4:05:06.789' or 'P1Y2M3DT4H5M6.789S', see xql.ParseInterval.
+func ParseInterval(s string) (Interval, error) {
+	months, days, us, err := xql.ParseInterval(s)
+	if nil != err {
+		return Interval{}, err
+	}
+	return Interval{Months: months, Days: days, Microseconds: us}, nil
+}
+
+func (i Interval) Declare(props xql.PropertySet) string {
+	return "interval"
+}
+
+// Duration
+// Which returns the Interval as a duration, a month is 30 days and a day is 24 hours.
+func (i Interval) Duration() time.Duration {
+	days := int64(i.Months)*30 + int64(i.Days)
+	return time.Duration(days)*24*time.Hour + time.Duration(i.Microseconds)*time.Microsecond
+}
+
+// String
+// Which returns the Interval in ISO 8601 format, like P1Y2M3DT4H5M6.789S.
+func (i Interval) String() string {
+	return xql.FormatInterval(i.Months, i.Days, i.Microseconds)
+}
+
+func (i *Interval) Scan(value interface{}) error {
+	var v sql.NullString
+	if err := v.Scan(value); err != nil {
+		return err
+	} else if !v.Valid {
+		*i = Interval{}
+		return nil
+	}
+	x, err := ParseInterval(v.String)
+	if nil != err {
+		return err
+	}
+	*i = x
+	return nil
+}
+
+func (i Interval) Value() (driver.Value, error) {
+	return i.String(), nil
+}
+
+// Duration
+// A time.Duration declared as interval, scanned by Interval.Duration, so months
+// and days of interval are taken as 30 days and 24 hours.
+type Duration time.Duration
+
+func (d Duration) Declare(props xql.PropertySet) string {
+	return "interval"
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) Scan(value interface{}) error {
+	var i Interval
+	if err := i.Scan(value); nil != err {
+		return err
+	}
+	*d = Duration(i.Duration())
+	return nil
+}
+
+func (d Duration) Value() (driver.Value, error) {
+	return IntervalOf(time.Duration(d)).Value()
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import "testing"
+
+func TestIntervalStyles(t *testing.T) {
+	want := Interval{Months: 14, Days: 3, Microseconds: 14706000000}
+	for _, s := range []string{
+		"1 year 2 mons 3 days 04:05:06",
+		"@ 1 year 2 mons 3 days 4 hours 5 mins 6 secs",
+		"1-2 3 4:05:06",
+		"P1Y2M3DT4H5M6S",
+	} {
+		var i Interval
+		if err := i.Scan([]byte(s)); nil != err {
+			t.Errorf("Scan(%s): %v", s, err)
+		} else if i != want {
+			t.Errorf("Scan(%s) = %+v, want %+v", s, i, want)
+		}
+	}
+	if v, _ := want.Value(); v != "P1Y2M3DT4H5M6S" {
+		t.Errorf("Value() = %v", v)
+	}
+	var d Duration
+	if err := d.Scan("1-0 1 0:00:01"); nil != err || d != Duration((361*24*3600+1)*1e9) {
+		t.Errorf("Duration Scan = %v, %v", d, err)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
 
 	"github.com/archsh/go.xql"
 )
@@ -23,18 +27,76 @@ func (j JSON) Value() (driver.Value, error) {
 	return JsonbValue(j)
 }
 
-type JSONB JSON
+// JSONB
+// A value of any type T stored as jsonb, or json with tag 'type=json'. SQL NULL is scanned
+// as zero value of T, and a value marshalled as null is written as NULL. For GIN index:
+//
+//	Attrs postgres.JSONB[map[string]interface{}] `xql:"index=gin,opclass=jsonb_path_ops"`
+//
+// So NULL can not be told apart from json values scanned as zero value of T, like {} of a
+// struct. Use xql.Field[postgres.JSONB[T]] to tell it, which is invalid for NULL only:
+//
+//	Attrs xql.Field[postgres.JSONB[Attributes]]
+type JSONB[T any] struct {
+	V T
+}
+
+// RawJSONB
+// The non-generic JSONB, keeping json text as it is. It replaces JSONB of previous
+// versions, which is generic now.
+type RawJSONB = JSONB[json.RawMessage]
 
-func (j JSONB) Declare(props xql.PropertySet) string {
-	return "JSONB"
+// NewJSONB
+// Which makes a JSONB of v.
+func NewJSONB[T any](v T) JSONB[T] {
+	return JSONB[T]{V: v}
 }
 
-func (j *JSONB) Scan(value interface{}) error {
-	return JsonbScan(j, value)
+func (j JSONB[T]) Declare(props xql.PropertySet) string {
+	if t, ok := props.GetString("type"); ok && t != "" {
+		return t
+	}
+	return "jsonb"
 }
 
-func (j JSONB) Value() (driver.Value, error) {
-	return JsonbValue(j)
+// Scan implements the sql.Scanner interface.
+func (j *JSONB[T]) Scan(value interface{}) error {
+	var v T
+	switch x := value.(type) {
+	case nil:
+	case []byte:
+		if e := json.Unmarshal(x, &v); nil != e {
+			return e
+		}
+	case string:
+		if e := json.Unmarshal([]byte(x), &v); nil != e {
+			return e
+		}
+	default:
+		return fmt.Errorf("converting %T to JSONB is unsupported", value)
+	}
+	j.V = v
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (j JSONB[T]) Value() (driver.Value, error) {
+	bs, e := json.Marshal(j.V)
+	if nil != e {
+		return nil, e
+	}
+	if string(bs) == "null" {
+		return nil, nil
+	}
+	return string(bs), nil
+}
+
+func (j JSONB[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.V)
+}
+
+func (j *JSONB[T]) UnmarshalJSON(bytes []byte) error {
+	return json.Unmarshal(bytes, &j.V)
 }
 
 func JsonbScan(dest interface{}, src interface{}) error {
@@ -60,3 +122,115 @@ func JsonbScan(dest interface{}, src interface{}) error {
 func JsonbValue(obj interface{}) (driver.Value, error) {
 	return json.Marshal(obj)
 }
+
+// jsonArg
+// The argument of json text of v, marshalled when the statement is executed, so an
+// error of marshalling is returned by it.
+type jsonArg struct {
+	v interface{}
+}
+
+func (j jsonArg) Value() (driver.Value, error) {
+	if raw, ok := j.v.(json.RawMessage); ok {
+		return string(raw), nil
+	}
+	bs, e := json.Marshal(j.v)
+	if nil != e {
+		return nil, fmt.Errorf("can not marshal %T to json: %w", j.v, e)
+	}
+	return string(bs), nil
+}
+
+// jsonPath
+// Which returns the text array literal of path, like '{a,0,b}'.
+func jsonPath(path []string) string {
+	var keys []string
+	for _, k := range path {
+		keys = append(keys, `"`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(k)+`"`)
+	}
+	return pq.QuoteLiteral("{" + strings.Join(keys, ",") + "}")
+}
+
+// JSONBGet
+// Which returns the expression of json value at key of field: field -> 'key', to be
+// used as field of filters and orders.
+func JSONBGet(field string, key string) string {
+	return fmt.Sprintf("%s -> %s", escapePGkw(field), pq.QuoteLiteral(key))
+}
+
+// JSONBGetText
+// Which returns the expression of text value at key of field: field ->> 'key'.
+//
+//	session.Table(DeviceTable).Where(postgres.JSONBGetText("attrs", "color"), "red")
+func JSONBGetText(field string, key string) string {
+	return fmt.Sprintf("%s ->> %s", escapePGkw(field), pq.QuoteLiteral(key))
+}
+
+// JSONBGetPath
+// Which returns the expression of json value at path of field: field #> '{a,b}'.
+func JSONBGetPath(field string, path ...string) string {
+	return fmt.Sprintf("%s #> %s", escapePGkw(field), jsonPath(path))
+}
+
+// JSONBGetPathText
+// Which returns the expression of text value at path of field: field #>> '{a,b}'.
+func JSONBGetPathText(field string, path ...string) string {
+	return fmt.Sprintf("%s #>> %s", escapePGkw(field), jsonPath(path))
+}
+
+// JSONBContains
+// Which makes a filter of field containing json of v: field @> v.
+func JSONBContains(field string, v interface{}) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "@>", Value: jsonArg{v}}
+}
+
+// JSONBContainedBy
+// Which makes a filter of field contained by json of v: field <@ v.
+func JSONBContainedBy(field string, v interface{}) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "<@", Value: jsonArg{v}}
+}
+
+// JSONBHasKey
+// Which makes a filter of field having top-level key: field ? key.
+func JSONBHasKey(field string, key string) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "?", Value: key}
+}
+
+// JSONBHasAnyKey
+// Which makes a filter of field having any of top-level keys: field ?| keys.
+func JSONBHasAnyKey(field string, keys ...string) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "?|", Value: pq.StringArray(keys)}
+}
+
+// JSONBHasAllKeys
+// Which makes a filter of field having all of top-level keys: field ?& keys.
+func JSONBHasAllKeys(field string, keys ...string) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "?&", Value: pq.StringArray(keys)}
+}
+
+// JSONBPathExists
+// Which makes a filter of jsonb_path_exists(field, path [, vars]), vars is marshalled
+// to json for variables used in path:
+//
+//	postgres.JSONBPathExists("attrs", "$.ports[*] ? (@ > $min)", map[string]int{"min": 1024})
+func JSONBPathExists(field string, path string, vars ...interface{}) xql.QueryFilter {
+	if len(vars) > 0 {
+		return xql.QueryFilter{Field: fmt.Sprintf("jsonb_path_exists(%s, $1::jsonpath, $2::jsonb)", escapePGkw(field)),
+			Args: []interface{}{path, jsonArg{vars[0]}}}
+	}
+	return xql.QueryFilter{Field: fmt.Sprintf("jsonb_path_exists(%s, $1::jsonpath)", escapePGkw(field)),
+		Args: []interface{}{path}}
+}
+
+// JSONBSet
+// Which makes an update column replacing value at path of field with json of v by
+// jsonb_set, missing keys are created:
+//
+//	session.Table(DeviceTable).Where("id", 1).Update([]xql.UpdateColumn{postgres.JSONBSet("attrs", []string{"color"}, "red")})
+func JSONBSet(field string, path []string, v interface{}) xql.UpdateColumn {
+	f := escapePGkw(field)
+	return xql.UpdateColumn{
+		Field: fmt.Sprintf("%s=jsonb_set(coalesce(%s, '{}'), $1::text[], $2::jsonb)", f, f),
+		Args:  []interface{}{pq.StringArray(path), jsonArg{v}},
+	}
+}
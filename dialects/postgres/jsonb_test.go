@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+type attributes struct {
+	Color string `json:"color,omitempty"`
+}
+
+func TestJSONBScan(t *testing.T) {
+	var j JSONB[attributes]
+	if err := j.Scan([]byte(`{"color":"red"}`)); nil != err || j.V.Color != "red" {
+		t.Fatalf("Scan = %+v, %v", j, err)
+	}
+	// NULL, {} and null are all the zero value of a struct.
+	for _, src := range []interface{}{nil, []byte(`{}`), "null"} {
+		j = NewJSONB(attributes{Color: "red"})
+		if err := j.Scan(src); nil != err {
+			t.Fatal(err)
+		}
+		if j.V != (attributes{}) {
+			t.Errorf("Scan(%s) = %+v, want zero", src, j)
+		}
+	}
+	// A map tells {} from NULL.
+	var m JSONB[map[string]int]
+	if err := m.Scan([]byte(`{}`)); nil != err || nil == m.V {
+		t.Errorf("Scan({}) = %#v, %v, want empty map", m.V, err)
+	}
+	if err := m.Scan(nil); nil != err || nil != m.V {
+		t.Errorf("Scan(NULL) = %#v, %v, want nil map", m.V, err)
+	}
+	if err := m.Scan(12); nil == err {
+		t.Error("Scan(int) should fail")
+	}
+	if err := m.Scan(`{"a":`); nil == err {
+		t.Error("Scan of invalid json should fail")
+	}
+}
+
+func TestJSONBValue(t *testing.T) {
+	if v, err := NewJSONB(attributes{Color: "red"}).Value(); nil != err || v != `{"color":"red"}` {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+	if v, err := NewJSONB(attributes{}).Value(); nil != err || v != `{}` {
+		t.Errorf("Value() of {} = %v, %v", v, err)
+	}
+	if v, err := (JSONB[map[string]int]{}).Value(); nil != err || nil != v {
+		t.Errorf("Value() of nil map = %v, %v, want NULL", v, err)
+	}
+}
+
+func TestFieldJSONB(t *testing.T) {
+	cases := []struct {
+		src   interface{}
+		valid bool
+		color string
+	}{
+		{nil, false, ""},
+		{[]byte(`{}`), true, ""},
+		{[]byte(`null`), true, ""},
+		{[]byte(`{"color":"red"}`), true, "red"},
+	}
+	for _, c := range cases {
+		f := xql.NewField(NewJSONB(attributes{Color: "blue"}))
+		if err := f.Scan(c.src); nil != err {
+			t.Fatalf("Scan(%s): %v", c.src, err)
+		}
+		if f.Valid != c.valid || f.V.V.Color != c.color {
+			t.Errorf("Scan(%s) = %+v, want valid %v color %q", c.src, f, c.valid, c.color)
+		}
+	}
+	if v, err := (xql.Field[JSONB[attributes]]{}).Value(); nil != err || nil != v {
+		t.Errorf("Value() of invalid Field = %v, %v, want NULL", v, err)
+	}
+	if v, err := xql.NewField(NewJSONB(attributes{})).Value(); nil != err || v != `{}` {
+		t.Errorf("Value() of valid Field = %v, %v, want {}", v, err)
+	}
+}
+
+func TestRawJSONB(t *testing.T) {
+	src := []byte(`{"b": [1, 2], "a": null}`)
+	var r RawJSONB
+	if err := r.Scan(src); nil != err {
+		t.Fatal(err)
+	}
+	copy(src, "xxxx") // Buffers of driver are reused.
+	if string(r.V) != `{"b": [1, 2], "a": null}` {
+		t.Errorf("Scan = %s", r.V)
+	}
+	if v, err := r.Value(); nil != err || v != `{"b":[1,2],"a":null}` {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+	if v, err := (RawJSONB{}).Value(); nil != err || nil != v {
+		t.Errorf("Value() of empty = %v, %v, want NULL", v, err)
+	}
+	if v, err := (RawJSONB{V: json.RawMessage(`{"a":`)}).Value(); nil == err {
+		t.Errorf("Value() of invalid json = %v, want error", v)
+	}
+}
+
+type device struct {
+	Id    int `xql:"type=serial,pk"`
+	Attrs xql.Field[JSONB[attributes]]
+	Raw   RawJSONB `xql:"type=json"`
+}
+
+func (d device) TableName() string { return "devices" }
+
+func TestDeclareJSONB(t *testing.T) {
+	table := xql.DeclareTable(device{})
+	attrs, _ := table.GetColumn("attrs")
+	if attrs.TypeDefine != "jsonb" || !attrs.Nullable {
+		t.Errorf("attrs declared %s, nullable %v", attrs.TypeDefine, attrs.Nullable)
+	}
+	raw, _ := table.GetColumn("raw")
+	if raw.TypeDefine != "json" {
+		t.Errorf("raw declared %s", raw.TypeDefine)
+	}
+}
+
+func TestJSONBFilters(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter xql.QueryFilter
+		where  string
+		args   []interface{}
+	}{
+		{"contains", JSONBContains("attrs", attributes{Color: "red"}), ` WHERE attrs @> $2`,
+			[]interface{}{"x", `{"color":"red"}`}},
+		{"path exists", JSONBPathExists("attrs", "$.color"), ` WHERE jsonb_path_exists(attrs, $2::jsonpath)`,
+			[]interface{}{"x", "$.color"}},
+		{"path exists with vars", JSONBPathExists("attrs", "$.ports[*] ? (@ > $min)", map[string]int{"min": 1024}),
+			` WHERE jsonb_path_exists(attrs, $2::jsonpath, $3::jsonb)`,
+			[]interface{}{"x", "$.ports[*] ? (@ > $min)", `{"min":1024}`}},
+	}
+	for _, c := range cases {
+		where, _, args := makeWhere([]xql.QueryFilter{c.filter}, 1, []interface{}{"x"})
+		for i, a := range args {
+			if v, ok := a.(driver.Valuer); ok {
+				args[i], _ = v.Value()
+			}
+		}
+		if where != c.where || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("%s: got %q %v, want %q %v", c.name, where, args, c.where, c.args)
+		}
+	}
+}
+
+func TestJSONBSet(t *testing.T) {
+	table := xql.DeclareTable(device{})
+	s, args, err := postgresDialect{}.Update(table, []xql.QueryFilter{{Field: "id", Operator: "=", Value: 1}},
+		xql.UpdateColumn{Field: "raw", Operator: "=", Value: "{}"}, JSONBSet("attrs", []string{"color"}, "red"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	want := `UPDATE devices SET raw=$1, attrs=jsonb_set(coalesce(attrs, '{}'), $2::text[], $3::jsonb) WHERE "id" = $4`
+	if s != want || len(args) != 4 {
+		t.Fatalf("got  %s %v\nwant %s", s, args, want)
+	}
+	if v, err := args[2].(driver.Valuer).Value(); nil != err || v != `"red"` {
+		t.Errorf("json of value = %v, %v", v, err)
+	}
+	// Values not marshalled fail when written, instead of panic.
+	f := JSONBContains("attrs", func() {})
+	if _, err := f.Value.(driver.Valuer).Value(); nil == err {
+		t.Error("expected error of marshalling a func")
+	}
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/archsh/go.xql"
+)
+
+// Money
+// A currency amount declared as money, scanned from the output of lc_monetary like
+// $1,234.56, ($1,234.56), -1.234,56 € or 1 234,56 €. The decimal separator is the last
+// of '.' and ',', or the only one unless it is followed by three digits and may group
+// thousands, like 1,234, which is an error: select money::numeric for such locales.
+// An empty Money is written as NULL.
+type Money xql.Decimal
+
+func (m Money) Declare(props xql.PropertySet) string {
+	return "money"
+}
+
+// Decimal
+// Which returns the amount as xql.Decimal.
+func (m Money) Decimal() xql.Decimal {
+	return xql.Decimal(m)
+}
+
+func (m Money) String() string {
+	return string(m)
+}
+
+// Scan implements the sql.Scanner interface, currency symbols and group separators are dropped.
+func (m *Money) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		*m = Money(xql.DecimalFromInt(v))
+		return nil
+	case nil:
+		return errors.New("converting NULL to Money is unsupported, use xql.Field[postgres.Money] or *postgres.Money")
+	default:
+		return fmt.Errorf("converting %T to Money is unsupported", value)
+	}
+	d, err := parseMoney(s)
+	if nil != err {
+		return err
+	}
+	*m = Money(d)
+	return nil
+}
+
+// parseMoney
+// Which parses amount s formatted by lc_monetary, currency symbols, spaces and group
+// separators are dropped.
+func parseMoney(s string) (xql.Decimal, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	var digits []byte
+	var seps []byte
+	var at []int // Number of digits before each separator
+	for _, r := range s {
+		switch {
+		case r == '-':
+			neg = true
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r == '.' || r == ',':
+			seps = append(seps, byte(r))
+			at = append(at, len(digits))
+		}
+	}
+	if len(digits) < 1 {
+		return "", errors.New("invalid money: " + s)
+	}
+	point := -1 // Digits before the decimal separator, -1 if none
+	if n := len(seps); n > 0 && strings.IndexByte(string(seps[:n-1]), seps[n-1]) < 0 {
+		// The last separator is the decimal separator if it differs from the others,
+		// or it is the only one and can not group thousands.
+		frac, whole := len(digits)-at[n-1], string(digits[:at[n-1]])
+		if n == 1 && frac == 3 && whole != "0" && len(whole) <= 3 {
+			return "", errors.New("ambiguous decimal separator of money: " + s)
+		}
+		point, seps, at = at[n-1], seps[:n-1], at[:n-1]
+	}
+	// Group separators left are the same, between groups of two or three digits.
+	for k := range seps {
+		end := len(digits)
+		if k+1 < len(at) {
+			end = at[k+1]
+		} else if point >= 0 {
+			end = point
+		}
+		if size := end - at[k]; seps[k] != seps[0] || at[k] == 0 || (size != 2 && size != 3) {
+			return "", errors.New("invalid money: " + s)
+		}
+	}
+	d := string(digits)
+	if point >= 0 {
+		d = d[:point] + "." + d[point:]
+		if point == 0 {
+			d = "0" + d
+		}
+	}
+	if neg {
+		d = "-" + d
+	}
+	return xql.ParseDecimal(d)
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return xql.Decimal(m).Value()
+}
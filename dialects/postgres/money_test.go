@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+func TestMoneyScan(t *testing.T) {
+	cases := []struct {
+		locale string
+		text   string
+		want   xql.Decimal
+	}{
+		{"en_US", "$1,234.56", "1234.56"},
+		{"en_US", "-$1,234.56", "-1234.56"},
+		{"en_US", "($1,234.56)", "-1234.56"},
+		{"en_US", "$1,234,567.00", "1234567.00"},
+		{"en_US", "$0.05", "0.05"},
+		{"de_DE", "1.234,56 €", "1234.56"},
+		{"de_DE", "-1.234.567,89 €", "-1234567.89"},
+		{"de_DE", "0,50 €", "0.50"},
+		{"fr_FR", "1 234,56 €", "1234.56"},
+		{"fr_FR", "1 234 567,00 €", "1234567.00"},
+		{"de_CH", "CHF 1'234.56", "1234.56"},
+		{"en_IN", "₹ 1,23,456.78", "123456.78"},
+		{"ja_JP", "￥1,234,567", "1234567"},
+		{"ar_BH", "BHD 1,234.567", "1234.567"},
+		{"numeric", "1234.567", "1234.567"},
+		{"numeric", "0.123", "0.123"},
+		{"numeric", "12", "12"},
+	}
+	for _, c := range cases {
+		t.Run(c.locale+" "+c.text, func(t *testing.T) {
+			var m Money
+			if err := m.Scan([]byte(c.text)); nil != err {
+				t.Fatal(err)
+			}
+			if m.Decimal() != c.want {
+				t.Errorf("got %s, want %s", m, c.want)
+			}
+		})
+	}
+}
+
+func TestMoneyScanInvalid(t *testing.T) {
+	for _, s := range []string{
+		"1,234",         // ja_JP thousands or de_DE with three decimals
+		"￥1.234",        // the same
+		"1.23,45.6",     // mixed separators
+		"1,2345.00",     // groups of four digits
+		"$",             // no digits
+		",123,456.00",   // no leading digits
+		"1.234.567,8,9", // two decimal separators
+	} {
+		var m Money
+		if err := m.Scan(s); nil == err {
+			t.Errorf("Scan(%q) = %s, want error", s, m)
+		}
+	}
+	var m Money
+	if err := m.Scan(nil); nil == err {
+		t.Error("Scan(NULL) should fail")
+	}
+	if err := m.Scan(int64(12)); nil != err || m != "12" {
+		t.Errorf("Scan(int64) = %s, %v", m, err)
+	}
+}
@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/archsh/go.xql"
+)
+
+// Inet
+// An IPv4 or IPv6 host address with optional netmask, NULL if invalid.
+type Inet struct {
+	netip.Prefix
+}
+
+// InetOf
+// Which makes an Inet of address, with netmask bits if given.
+func InetOf(addr netip.Addr, bits ...int) Inet {
+	n := addr.BitLen()
+	if len(bits) > 0 {
+		n = bits[0]
+	}
+	return Inet{netip.PrefixFrom(addr, n)}
+}
+
+// ParseInet
+// Which parses an address like 192.168.1.5 or 192.168.1.5/24.
+func ParseInet(s string) (Inet, error) {
+	if !strings.Contains(s, "/") {
+		addr, e := netip.ParseAddr(s)
+		if nil != e {
+			return Inet{}, e
+		}
+		return InetOf(addr), nil
+	}
+	p, e := netip.ParsePrefix(s)
+	return Inet{p}, e
+}
+
+func (i Inet) Declare(props xql.PropertySet) string {
+	return "inet"
+}
+
+// String
+// Which returns the address, with netmask unless it is a single host.
+func (i Inet) String() string {
+	if !i.IsValid() {
+		return ""
+	}
+	if i.Bits() == i.Addr().BitLen() {
+		return i.Addr().String()
+	}
+	return i.Prefix.String()
+}
+
+func (i *Inet) Scan(value interface{}) error {
+	var v sql.NullString
+	if err := v.Scan(value); err != nil {
+		return err
+	} else if !v.Valid {
+		*i = Inet{}
+		return nil
+	}
+	x, err := ParseInet(v.String)
+	if nil != err {
+		return err
+	}
+	*i = x
+	return nil
+}
+
+func (i Inet) Value() (driver.Value, error) {
+	if !i.IsValid() {
+		return nil, nil
+	}
+	return i.String(), nil
+}
+
+// Cidr
+// An IPv4 or IPv6 network, NULL if invalid. Host bits are cleared when written.
+type Cidr struct {
+	netip.Prefix
+}
+
+func (c Cidr) Declare(props xql.PropertySet) string {
+	return "cidr"
+}
+
+func (c *Cidr) Scan(value interface{}) error {
+	var v sql.NullString
+	if err := v.Scan(value); err != nil {
+		return err
+	} else if !v.Valid {
+		*c = Cidr{}
+		return nil
+	}
+	p, err := netip.ParsePrefix(v.String)
+	if nil != err {
+		return err
+	}
+	*c = Cidr{p}
+	return nil
+}
+
+func (c Cidr) Value() (driver.Value, error) {
+	if !c.IsValid() {
+		return nil, nil
+	}
+	return c.Masked().String(), nil
+}
+
+// MacAddr
+// A MAC address, declared as macaddr, or macaddr8 with tag 'size=8'. NULL if empty.
+type MacAddr net.HardwareAddr
+
+func (m MacAddr) Declare(props xql.PropertySet) string {
+	if size, _ := props.GetUInt("size", 6); size == 8 {
+		return "macaddr8"
+	}
+	return "macaddr"
+}
+
+func (m MacAddr) String() string {
+	return net.HardwareAddr(m).String()
+}
+
+func (m *MacAddr) Scan(value interface{}) error {
+	var v sql.NullString
+	if err := v.Scan(value); err != nil {
+		return err
+	} else if !v.Valid {
+		*m = nil
+		return nil
+	}
+	hw, err := net.ParseMAC(v.String)
+	if nil != err {
+		return err
+	}
+	*m = MacAddr(hw)
+	return nil
+}
+
+func (m MacAddr) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	return m.String(), nil
+}
+
+// inetValue
+// Which converts addresses and networks to text for filters.
+func inetValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case netip.Addr:
+		return x.String()
+	case netip.Prefix:
+		return x.String()
+	case fmt.Stringer:
+		return x.String()
+	}
+	return v
+}
+
+// InetContainedBy
+// Which makes a filter of field contained by network v: field << v.
+func InetContainedBy(field string, v interface{}) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "<<", Value: inetValue(v)}
+}
+
+// InetContainedByOrEquals
+// Which makes a filter of field contained by or equal to network v: field <<= v.
+func InetContainedByOrEquals(field string, v interface{}) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "<<=", Value: inetValue(v)}
+}
+
+// InetContains
+// Which makes a filter of network field containing v: field >> v.
+func InetContains(field string, v interface{}) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: ">>", Value: inetValue(v)}
+}
+
+// InetContainsOrEquals
+// Which makes a filter of network field containing or equal to v: field >>= v.
+func InetContainsOrEquals(field string, v interface{}) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: ">>=", Value: inetValue(v)}
+}
@@ -2,12 +2,17 @@ package postgres
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/archsh/go.xql"
+	"github.com/lib/pq"
 )
 
 type postgresDialect struct {
@@ -126,7 +131,12 @@ func makeConstraints(t *xql.Table, idx int, c ...*xql.Constraint) (ret []string)
 		case xql.ConstraintCheck:
 			ret = append(ret, fmt.Sprintf("CONSTRAINT %s_check CHECK (%s)", nameStr, x.Statement))
 		case xql.ConstraintExclude:
-			ret = append(ret, fmt.Sprintf("CONSTRAINT %s_exclude EXCLUDE USING %s", nameStr, x.Statement))
+			statement := x.Statement
+			if !strings.Contains(statement, "(") && len(x.Columns) == 1 {
+				// Tag exclude=col1;col2 on a range column.
+				statement = ExcludeOverlap(x.Columns[0].FieldName, strings.Split(statement, ";")...)
+			}
+			ret = append(ret, fmt.Sprintf("CONSTRAINT %s_exclude EXCLUDE USING %s", nameStr, statement))
 		case xql.ConstraintForeignKey:
 			onDelete := ""
 			onUpdate := ""
@@ -168,7 +178,11 @@ func makeIndexes(t *xql.Table, idx int, i ...*xql.Index) (ret []string) {
 			tp = "USING gin"
 		}
 		// CREATE INDEX test2_mm_idx ON test2 (major, minor);
-		s := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s %s (\"%s\");", ii.Name, t.BaseTableName(), tp, strings.Join(fs, ","))
+		cols := fmt.Sprintf("\"%s\"", strings.Join(fs, ","))
+		if ii.Class != "" {
+			cols += " " + ii.Class
+		}
+		s := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s %s (%s);", ii.Name, t.BaseTableName(), tp, cols)
 		ret = append(ret, s)
 	}
 	return
@@ -208,8 +222,13 @@ func (pb postgresDialect) Create(t *xql.Table, options ...interface{}) (s string
 	createSQL = "CREATE TABLE IF NOT EXISTS " + tableName + " ( "
 	var indexes []*xql.Index
 	var cols []string
+	var types []string
+	var excludes []*xql.Constraint
 	for _, c := range t.GetColumns() {
-		colStr := fmt.Sprintf(`%s %s`, escapePGkw(c.FieldName), c.TypeDefine)
+		if c.EnumType != "" {
+			types = append(types, makeEnumType(c))
+		}
+		colStr := fmt.Sprintf(`%s %s`, escapePGkw(c.FieldName), c.TypeFor("postgres"))
 		if c.Default != nil {
 			colStr = fmt.Sprintf(`%s DEFAULT %s`, colStr, c.Default)
 		}
@@ -218,65 +237,65 @@ func (pb postgresDialect) Create(t *xql.Table, options ...interface{}) (s string
 		}
 		cols = append(cols, colStr)
 		indexes = append(indexes, c.Indexes...)
+		for _, x := range c.Constraints {
+			if x.Type == xql.ConstraintExclude {
+				excludes = append(excludes, x)
+			}
+		}
 	}
-	cols = append(cols, makeConstraints(t, 0, t.GetConstraints()...)...)
+	cols = append(cols, makeConstraints(t, 0, append(excludes, t.GetConstraints()...)...)...)
 	createSQL = createSQL + strings.Join(cols, ", ") + " );"
 	indexes = append(indexes, t.GetIndexes()...)
 	indexesStrings := makeIndexes(t, 0, indexes...)
-	s = strings.Join(append([]string{createSQL}, indexesStrings...), "\n")
+	s = strings.Join(append(append(types, createSQL), indexesStrings...), "\n")
 	return s, args, err
 }
 
+// makeEnumType
+// Which makes the statement creating enum type of column, if not exists.
+func makeEnumType(c *xql.Column) string {
+	var values []string
+	for _, x := range c.EnumValues() {
+		values = append(values, pq.QuoteLiteral(x))
+	}
+	return fmt.Sprintf("DO $$ BEGIN CREATE TYPE %s AS ENUM (%s); EXCEPTION WHEN duplicate_object THEN NULL; END $$;",
+		c.EnumType, strings.Join(values, ","))
+}
+
+// AddEnumValue
+// Which adds value to enum type name if not exists, after the value of after if given.
+// Use it in migrations when values are added to an enum column.
+func AddEnumValue(db *sql.DB, name string, value string, after ...string) error {
+	s := fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s", name, pq.QuoteLiteral(value))
+	if len(after) > 0 {
+		s += " AFTER " + pq.QuoteLiteral(after[0])
+	}
+	_, e := db.Exec(s)
+	return e
+}
+
 // Select
 // Implement the IDialect interface for select values.
 func (pb postgresDialect) Select(t *xql.Table, cols []xql.QueryColumn, filters []xql.QueryFilter, orders []xql.QueryOrder, lockFor string, offset int64, limit int64) (s string, args []interface{}, err error) {
 	var colNames []string
 	for _, x := range cols {
-		colNames = append(colNames, x.String())
+		colNames = append(colNames, x.String(true))
 	}
 	s = fmt.Sprintf("SELECT %s FROM ", strings.Join(colNames, ","))
 	s += t.TableName()
-	var n int
-	for i, f := range filters {
-		var cause string
-		switch f.Condition {
-		case xql.ConditionAnd:
-			cause = "AND"
-		case xql.ConditionOr:
-			cause = "OR"
-		}
-		if i == 0 {
-			cause = "WHERE"
-		}
-		if f.Operator == "" {
-			s = fmt.Sprintf(`%s %s %s`, s, cause, escapePGkw(f.Field))
-		} else if f.Reversed {
-			n += 1
-			if f.Function != "" {
-				s = fmt.Sprintf(`%s %s %s($%d) %s %s`, s, cause, f.Function, n, f.Operator, escapePGkw(f.Field))
-			} else {
-				s = fmt.Sprintf(`%s %s $%d %s %s`, s, cause, n, f.Operator, escapePGkw(f.Field))
-			}
-
-			args = append(args, f.Value)
-		} else {
-			n += 1
-			if f.Function != "" {
-				s = fmt.Sprintf(`%s %s %s %s %s($%d)`, s, cause, escapePGkw(f.Field), f.Operator, f.Function, n)
-			} else {
-				s = fmt.Sprintf(`%s %s %s %s $%d`, s, cause, escapePGkw(f.Field), f.Operator, n)
-			}
-
-			args = append(args, f.Value)
-		}
-	}
+	where, n, args := makeWhere(filters, 0, args)
+	s += where
 	var sOrders []string
 	for _, o := range orders {
+		field := escapePGkw(o.Field)
+		if len(o.Args) > 0 {
+			field, n, args = bindRaw(o.Field, o.Args, n, args)
+		}
 		switch o.Type {
 		case xql.OrderAsc:
-			sOrders = append(sOrders, fmt.Sprintf(`%s ASC`, escapePGkw(o.Field)))
+			sOrders = append(sOrders, fmt.Sprintf(`%s ASC`, field))
 		case xql.OrderDesc:
-			sOrders = append(sOrders, fmt.Sprintf(`%s DESC`, escapePGkw(o.Field)))
+			sOrders = append(sOrders, fmt.Sprintf(`%s DESC`, field))
 		}
 	}
 	if len(sOrders) > 0 {
@@ -295,20 +314,61 @@ func (pb postgresDialect) Select(t *xql.Table, cols []xql.QueryColumn, filters [
 	return
 }
 
-func isEmptyValue(v reflect.Value) bool {
-	switch v.Kind() {
-	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
-		return v.Len() == 0
-	case reflect.Bool:
-		return !v.Bool()
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return v.Int() == 0
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		return v.Uint() == 0
-	case reflect.Float32, reflect.Float64:
-		return v.Float() == 0
-	case reflect.Interface, reflect.Ptr:
-		return v.IsNil()
+// LockFor
+// Implement the IDialectLocker interface for row-locking options.
+func (pb postgresDialect) LockFor(lock xql.QueryLock) (s string, err error) {
+	switch lock.Strength {
+	case xql.LockForUpdate:
+		s = "UPDATE"
+	case xql.LockForNoKeyUpdate:
+		s = "NO KEY UPDATE"
+	case xql.LockForShare:
+		s = "SHARE"
+	case xql.LockForKeyShare:
+		s = "KEY SHARE"
+	case xql.LockNone:
+		if lock.Wait != xql.LockWaitDefault || len(lock.Of) > 0 {
+			err = errors.New("lock strength required for lock options")
+		}
+		return
+	default:
+		err = fmt.Errorf("unknown lock strength: %d", lock.Strength)
+		return
+	}
+	if len(lock.Of) > 0 {
+		var tables []string
+		for _, t := range lock.Of {
+			if !isPureName(t) {
+				err = errors.New("invalid table name to lock: " + t)
+				return
+			}
+			tables = append(tables, escapePGkw(t))
+		}
+		s += " OF " + strings.Join(tables, ",")
+	}
+	switch lock.Wait {
+	case xql.LockNoWait:
+		s += " NOWAIT"
+	case xql.LockSkipLocked:
+		s += " SKIP LOCKED"
+	case xql.LockWaitDefault:
+	default:
+		err = fmt.Errorf("unknown lock wait policy: %d", lock.Wait)
+	}
+	return
+}
+
+var pureNameRex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+func isPureName(s string) bool {
+	return pureNameRex.MatchString(s)
+}
+
+func inSlice(a string, ls []string) bool {
+	for _, s := range ls {
+		if a == s {
+			return true
+		}
 	}
 	return false
 }
@@ -321,7 +381,9 @@ func (pb postgresDialect) Insert(t *xql.Table, obj interface{}, col ...string) (
 	var cols []string
 	var vals []string
 	r := reflect.ValueOf(obj)
-	if len(col) < 1 {
+	// Explicit columns are always written, otherwise columns omitted for their values are skipped.
+	explicit := len(col) > 0
+	if !explicit {
 		for _, x := range t.GetColumns() {
 			col = append(col, x.FieldName)
 		}
@@ -333,9 +395,8 @@ func (pb postgresDialect) Insert(t *xql.Table, obj interface{}, col ...string) (
 			continue
 		}
 		fv := reflect.Indirect(r).FieldByName(column.ElemName)
-		fv.Kind()
 		//if fv.Interface() == reflect.Zero(fv.Type()).Interface() {
-		if !fv.IsValid() || isEmptyValue(fv) {
+		if !fv.IsValid() || (!explicit && column.Omitted(fv, true)) {
 			//if ( fv.Worker() == reflect.Ptr && fv.IsNil() ) || reflect.Zero(fv.Type()).Interface() == fv.Interface() {
 			//    if column.PrimaryKey && column.Default == nil {
 			//        continue
@@ -350,6 +411,10 @@ func (pb postgresDialect) Insert(t *xql.Table, obj interface{}, col ...string) (
 		vals = append(vals, fmt.Sprintf("$%d", i))
 
 	}
+	if len(cols) < 1 {
+		s += " DEFAULT VALUES"
+		return
+	}
 	s = fmt.Sprintf("%s (%s) VALUES(%s)", s, strings.Join(cols, ","), strings.Join(vals, ","))
 	return
 }
@@ -362,7 +427,9 @@ func (pb postgresDialect) InsertWithInsertedId(t *xql.Table, obj interface{}, in
 	var cols []string
 	var vals []string
 	r := reflect.ValueOf(obj)
-	if len(col) < 1 {
+	// Explicit columns are always written, otherwise columns omitted for their values are skipped.
+	explicit := len(col) > 0
+	if !explicit {
 		for _, x := range t.GetColumns() {
 			col = append(col, x.FieldName)
 		}
@@ -374,9 +441,8 @@ func (pb postgresDialect) InsertWithInsertedId(t *xql.Table, obj interface{}, in
 			continue
 		}
 		fv := reflect.Indirect(r).FieldByName(column.ElemName)
-		fv.Kind()
 		//if fv.Interface() == reflect.Zero(fv.Type()).Interface() {
-		if !fv.IsValid() || isEmptyValue(fv) {
+		if !fv.IsValid() || (!explicit && column.Omitted(fv, true)) {
 			//if ( fv.Worker() == reflect.Ptr && fv.IsNil() ) || reflect.Zero(fv.Type()).Interface() == fv.Interface() {
 			//    if column.PrimaryKey && column.Default == nil {
 			//        continue
@@ -391,14 +457,198 @@ func (pb postgresDialect) InsertWithInsertedId(t *xql.Table, obj interface{}, in
 		vals = append(vals, fmt.Sprintf("$%d", i))
 
 	}
+	if len(cols) < 1 {
+		s = fmt.Sprintf("%s DEFAULT VALUES RETURNING %s", s, insertedId)
+		return
+	}
 	s = fmt.Sprintf("%s (%s) VALUES(%s) RETURNING %s",
 		s, strings.Join(cols, ","), strings.Join(vals, ","), insertedId)
 	return
 }
 
+func makeConflict(conflict *xql.QueryConflict, cols []string) (string, error) {
+	if nil == conflict || conflict.Action == xql.ConflictNone {
+		return "", nil
+	}
+	var target []string
+	for _, c := range conflict.Columns {
+		target = append(target, escapePGkw(c))
+	}
+	s := " ON CONFLICT"
+	if len(target) > 0 {
+		s = fmt.Sprintf("%s (%s)", s, strings.Join(target, ","))
+	}
+	switch conflict.Action {
+	case xql.ConflictDoNothing:
+		return s + " DO NOTHING", nil
+	case xql.ConflictDoUpdate:
+		if len(target) < 1 {
+			return "", errors.New("conflict target required for DO UPDATE")
+		}
+		var sets []string
+		for _, c := range cols {
+			if inSlice(c, conflict.Columns) {
+				continue
+			}
+			sets = append(sets, fmt.Sprintf("%s=EXCLUDED.%s", escapePGkw(c), escapePGkw(c)))
+		}
+		if len(sets) < 1 {
+			return s + " DO NOTHING", nil
+		}
+		return s + " DO UPDATE SET " + strings.Join(sets, ","), nil
+	}
+	return "", errors.New("unknown conflict action")
+}
+
+// InsertSelect
+// Implement the IDialectInsertSelect interface to generate INSERT INTO ... SELECT statement
+func (pb postgresDialect) InsertSelect(t *xql.Table, cols []string, src *xql.Table, queries []xql.QueryColumn, filters []xql.QueryFilter, orders []xql.QueryOrder, lockFor string, offset int64, limit int64, conflict *xql.QueryConflict) (s string, args []interface{}, err error) {
+	var sel, onConflict string
+	if sel, args, err = pb.Select(src, queries, filters, orders, lockFor, offset, limit); nil != err {
+		return
+	}
+	if onConflict, err = makeConflict(conflict, cols); nil != err {
+		return
+	}
+	var names []string
+	for _, c := range cols {
+		names = append(names, escapePGkw(c))
+	}
+	s = fmt.Sprintf("INSERT INTO %s (%s) %s%s", t.TableName(), strings.Join(names, ","), sel, onConflict)
+	return
+}
+
+func isListValue(v interface{}) bool {
+	if nil == v {
+		return false
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return false
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Slice:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// makeCondition
+// Which renders a filter, n is the count of args already used. Value of IN and
+// NOT IN operators given as a slice is expanded to a list of parameters.
+func makeCondition(f xql.QueryFilter, n int, args []interface{}) (string, int, []interface{}) {
+	if len(f.Group) > 0 {
+		var cond string
+		cond, n, args = makeConditions(f.Group, n, args)
+		return "(" + cond + ")", n, args
+	}
+	if f.Operator == "" {
+		if len(f.Args) > 0 {
+			return bindRaw(f.Field, f.Args, n, args)
+		}
+		return escapePGkw(f.Field), n, args
+	}
+	if nil == f.Value && !f.Reversed {
+		// NULL never equals to anything.
+		switch strings.ToUpper(strings.TrimSpace(f.Operator)) {
+		case "=", "IS":
+			return escapePGkw(f.Field) + " IS NULL", n, args
+		case "<>", "!=", "IS NOT":
+			return escapePGkw(f.Field) + " IS NOT NULL", n, args
+		}
+	}
+	param := func(v interface{}) string {
+		n += 1
+		args = append(args, v)
+		if f.Function != "" {
+			return fmt.Sprintf("%s($%d)", f.Function, n)
+		}
+		return fmt.Sprintf("$%d", n)
+	}
+	var value string
+	if op := strings.ToUpper(strings.TrimSpace(f.Operator)); (op == "IN" || op == "NOT IN") && isListValue(f.Value) {
+		rv := reflect.ValueOf(f.Value)
+		if rv.Len() < 1 {
+			// Nothing is in an empty list.
+			if op == "IN" {
+				return "FALSE", n, args
+			}
+			return "TRUE", n, args
+		}
+		var params []string
+		for i := 0; i < rv.Len(); i++ {
+			params = append(params, param(rv.Index(i).Interface()))
+		}
+		value = "(" + strings.Join(params, ",") + ")"
+	} else {
+		value = param(f.Value)
+	}
+	if f.Reversed {
+		return fmt.Sprintf(`%s %s %s`, value, f.Operator, escapePGkw(f.Field)), n, args
+	}
+	return fmt.Sprintf(`%s %s %s`, escapePGkw(f.Field), f.Operator, value), n, args
+}
+
+// makeConditions
+// Which renders filters joined with AND/OR, n is the count of args already used.
+func makeConditions(filters []xql.QueryFilter, n int, args []interface{}) (string, int, []interface{}) {
+	var s, cond string
+	for i, f := range filters {
+		cond, n, args = makeCondition(f, n, args)
+		if i == 0 {
+			s = cond
+			continue
+		}
+		switch f.Condition {
+		case xql.ConditionOr:
+			s = fmt.Sprintf(`%s OR %s`, s, cond)
+		default:
+			s = fmt.Sprintf(`%s AND %s`, s, cond)
+		}
+	}
+	return s, n, args
+}
+
+var rawParamRex = regexp.MustCompile(`'(?:[^']|'')*'|\$(\d+)`)
+
+// bindRaw
+// Which renumbers parameters $1, $2 ... of a raw expression after n args already
+// used, and appends params. Quoted literals are left as they are.
+func bindRaw(expr string, params []interface{}, n int, args []interface{}) (string, int, []interface{}) {
+	expr = rawParamRex.ReplaceAllStringFunc(expr, func(m string) string {
+		if m[0] != '$' {
+			return m
+		}
+		i, _ := strconv.Atoi(m[1:])
+		return fmt.Sprintf("$%d", n+i)
+	})
+	return expr, n + len(params), append(args, params...)
+}
+
+// makeWhere
+// Which renders filters as WHERE clause, n is the count of args already used.
+func makeWhere(filters []xql.QueryFilter, n int, args []interface{}) (string, int, []interface{}) {
+	if len(filters) < 1 {
+		return "", n, args
+	}
+	var cond string
+	cond, n, args = makeConditions(filters, n, args)
+	return " WHERE " + cond, n, args
+}
+
 func makeSetStr(uc xql.UpdateColumn, i int, args []interface{}) ([]interface{}, string, int) {
 	if uc.Operator == "" {
+		if len(uc.Args) > 0 {
+			var set string
+			set, i, args = bindRaw(uc.Field, uc.Args, i, args)
+			return args, set, i
+		}
 		return args, fmt.Sprintf(`%s`, uc.Field), i
+	} else if uc.Operator == "+=" {
+		args = append(args, uc.Value)
+		f := escapePGkw(uc.Field)
+		return args, fmt.Sprintf(`%s=%s+$%d`, f, f, i+1), i + 1
 	} else {
 		args = append(args, uc.Value)
 		return args, fmt.Sprintf(`%s%s$%d`, escapePGkw(uc.Field), uc.Operator, i+1), i + 1
@@ -428,40 +678,8 @@ func (pb postgresDialect) Update(t *xql.Table, filters []xql.QueryFilter, cols .
 		}
 	}
 
-	//var n int
-	for i, f := range filters {
-		var cause string
-		switch f.Condition {
-		case xql.ConditionAnd:
-			cause = "AND"
-		case xql.ConditionOr:
-			cause = "OR"
-		}
-		if i == 0 {
-			cause = "WHERE"
-		}
-		if f.Operator == "" {
-			s = fmt.Sprintf(`%s %s %s`, s, cause, f.Field)
-		} else if f.Reversed {
-			n += 1
-			if f.Function != "" {
-				s = fmt.Sprintf(`%s %s %s($%d) %s %s`, s, cause, f.Function, n, f.Operator, escapePGkw(f.Field))
-			} else {
-				s = fmt.Sprintf(`%s %s $%d %s %s`, s, cause, n, f.Operator, escapePGkw(f.Field))
-			}
-
-			args = append(args, f.Value)
-		} else {
-			n += 1
-			if f.Function != "" {
-				s = fmt.Sprintf(`%s %s %s %s %s($%d)`, s, cause, escapePGkw(f.Field), f.Operator, f.Function, n)
-			} else {
-				s = fmt.Sprintf(`%s %s %s %s $%d`, s, cause, escapePGkw(f.Field), f.Operator, n)
-			}
-
-			args = append(args, f.Value)
-		}
-	}
+	where, _, args := makeWhere(filters, n, args)
+	s += where
 	return
 }
 
@@ -470,40 +688,8 @@ func (pb postgresDialect) Update(t *xql.Table, filters []xql.QueryFilter, cols .
 func (pb postgresDialect) Delete(t *xql.Table, filters []xql.QueryFilter) (s string, args []interface{}, err error) {
 	s = "DELETE FROM "
 	s += t.TableName()
-	var n int
-	for i, f := range filters {
-		var cause string
-		switch f.Condition {
-		case xql.ConditionAnd:
-			cause = "AND"
-		case xql.ConditionOr:
-			cause = "OR"
-		}
-		if i == 0 {
-			cause = "WHERE"
-		}
-		if f.Operator == "" {
-			s = fmt.Sprintf(`%s %s %s`, s, cause, f.Field)
-		} else if f.Reversed {
-			n += 1
-			if f.Function != "" {
-				s = fmt.Sprintf(`%s %s %s($%d) %s %s`, s, cause, f.Function, n, f.Operator, escapePGkw(f.Field))
-			} else {
-				s = fmt.Sprintf(`%s %s $%d %s %s`, s, cause, n, f.Operator, escapePGkw(f.Field))
-			}
-
-			args = append(args, f.Value)
-		} else {
-			n += 1
-			if f.Function != "" {
-				s = fmt.Sprintf(`%s %s %s %s %s($%d)`, s, cause, escapePGkw(f.Field), f.Operator, f.Function, n)
-			} else {
-				s = fmt.Sprintf(`%s %s %s %s $%d`, s, cause, escapePGkw(f.Field), f.Operator, n)
-			}
-
-			args = append(args, f.Value)
-		}
-	}
+	where, _, args := makeWhere(filters, 0, args)
+	s += where
 	return
 }
 
@@ -537,4 +723,5 @@ func InitializeUUID(db *sql.DB, schema ...string) error {
 // Register the dialect.
 func init() {
 	xql.RegisterDialect("postgres", &postgresDialect{})
+	xql.RegisterDialectType("postgres", json.RawMessage{}, func(props xql.PropertySet) string { return "jsonb" })
 }
@@ -0,0 +1,319 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+// Range
+// A range of T, declared as int4range (int, int32), int8range (int64), numrange
+// (xql.Decimal, xql.Numeric) or tstzrange (time.Time) by default, other range types
+// like daterange or tsrange are given by tag 'type':
+//
+//	During postgres.Range[time.Time] `xql:"exclude=room"`
+//	Period postgres.Range[time.Time] `xql:"type=daterange"`
+//
+// A Range is NULL unless Valid, which is set by NewRange, EmptyRange and Scan. Use
+// EmptyRange for an empty range. Bounds -infinity and infinity of dates and timestamps
+// are scanned as unbounded.
+type Range[T any] struct {
+	Lower    T
+	Upper    T
+	LowerInf bool // Lower unbounded
+	UpperInf bool // Upper unbounded
+	LowerInc bool // Lower bound inclusive
+	UpperInc bool // Upper bound inclusive
+	Empty    bool
+	Valid    bool // Valid is true if not NULL
+}
+
+// NewRange
+// Which makes a range of lower and upper, bounds is one of "[)" (default), "[]", "()" and "(]".
+func NewRange[T any](lower T, upper T, bounds ...string) Range[T] {
+	b := "[)"
+	if len(bounds) > 0 && len(bounds[0]) == 2 {
+		b = bounds[0]
+	}
+	return Range[T]{Lower: lower, Upper: upper, LowerInc: b[0] == '[', UpperInc: b[1] == ']', Valid: true}
+}
+
+// EmptyRange
+// Which makes an empty range.
+func EmptyRange[T any]() Range[T] {
+	return Range[T]{Empty: true, Valid: true}
+}
+
+// Declare
+// Which returns the range type of T, empty if T has no range type, see TryDeclare.
+func (r Range[T]) Declare(props xql.PropertySet) string {
+	s, _ := r.TryDeclare(props)
+	return s
+}
+
+// TryDeclare
+// Which returns the range type of T, an error if T has no range type and no 'type'
+// is given by tag.
+func (r Range[T]) TryDeclare(props xql.PropertySet) (string, error) {
+	if t, ok := props.GetString("type"); ok && t != "" {
+		return t, nil
+	}
+	var v T
+	switch interface{}(v).(type) {
+	case int, int32:
+		return "int4range", nil
+	case int64:
+		return "int8range", nil
+	case xql.Decimal, xql.Numeric:
+		return "numrange", nil
+	case time.Time:
+		return "tstzrange", nil
+	}
+	return "", fmt.Errorf("Unknown range type of %T, declare it with tag 'type'", v)
+}
+
+// String
+// Which returns the range in text format of PostgreSQL, like [1,10).
+func (r Range[T]) String() string {
+	if r.Empty {
+		return "empty"
+	}
+	var sb strings.Builder
+	if r.LowerInc && !r.LowerInf {
+		sb.WriteByte('[')
+	} else {
+		sb.WriteByte('(')
+	}
+	if !r.LowerInf {
+		sb.WriteString(quoteBound(formatBound(r.Lower)))
+	}
+	sb.WriteByte(',')
+	if !r.UpperInf {
+		sb.WriteString(quoteBound(formatBound(r.Upper)))
+	}
+	if r.UpperInc && !r.UpperInf {
+		sb.WriteByte(']')
+	} else {
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+// Value implements the driver Valuer interface, a Range not Valid is NULL.
+func (r Range[T]) Value() (driver.Value, error) {
+	if !r.Valid {
+		return nil, nil
+	}
+	return r.String(), nil
+}
+
+// Scan implements the sql.Scanner interface, NULL makes the zero Range, which is not Valid.
+func (r *Range[T]) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*r = Range[T]{}
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("converting %T to Range is unsupported", src)
+	}
+	x := Range[T]{Valid: true}
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "empty") {
+		x.Empty = true
+		*r = x
+		return nil
+	}
+	if len(s) < 3 || !strings.ContainsRune("[(", rune(s[0])) || !strings.ContainsRune("])", rune(s[len(s)-1])) {
+		return errors.New("invalid range: " + s)
+	}
+	x.LowerInc, x.UpperInc = s[0] == '[', s[len(s)-1] == ']'
+	lower, upper, err := splitBounds(s[1 : len(s)-1])
+	if nil != err {
+		return err
+	}
+	_, timed := interface{}(x.Lower).(time.Time)
+	if x.LowerInf = nil == lower || timed && isInfinity(*lower); !x.LowerInf {
+		if err := parseBound(*lower, &x.Lower); nil != err {
+			return err
+		}
+	}
+	if x.UpperInf = nil == upper || timed && isInfinity(*upper); !x.UpperInf {
+		if err := parseBound(*upper, &x.Upper); nil != err {
+			return err
+		}
+	}
+	// Unbounded sides are exclusive, as PostgreSQL returns them.
+	x.LowerInc = x.LowerInc && !x.LowerInf
+	x.UpperInc = x.UpperInc && !x.UpperInf
+	*r = x
+	return nil
+}
+
+// splitBounds
+// Which splits the bounds of a range, nil for an unbounded side.
+func splitBounds(s string) (*string, *string, error) {
+	var bounds [2]*string
+	var sb strings.Builder
+	var quoted, present bool
+	i := 0
+	for j := 0; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == '\\' && j+1 < len(s):
+			j++
+			sb.WriteByte(s[j])
+			present = true
+		case c == '"' && quoted && j+1 < len(s) && s[j+1] == '"':
+			j++
+			sb.WriteByte('"')
+		case c == '"':
+			quoted = !quoted
+			present = true
+		case c == ',' && !quoted:
+			if i > 0 {
+				return nil, nil, errors.New("invalid range bounds: " + s)
+			}
+			if present {
+				b := sb.String()
+				bounds[i] = &b
+			}
+			sb.Reset()
+			present = false
+			i++
+		default:
+			sb.WriteByte(c)
+			present = true
+		}
+	}
+	if i != 1 || quoted {
+		return nil, nil, errors.New("invalid range bounds: " + s)
+	}
+	if present {
+		b := sb.String()
+		bounds[1] = &b
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// isInfinity
+// Which tells if bound s is -infinity or infinity of dates and timestamps.
+func isInfinity(s string) bool {
+	return strings.EqualFold(s, "infinity") || strings.EqualFold(s, "-infinity")
+}
+
+func quoteBound(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func formatBound(v interface{}) string {
+	switch x := v.(type) {
+	case time.Time:
+		return x.Format("2006-01-02 15:04:05.999999Z07:00")
+	case driver.Valuer:
+		if dv, e := x.Value(); nil == e && nil != dv {
+			return fmt.Sprint(dv)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+var boundTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseBound(s string, dst interface{}) error {
+	var err error
+	switch p := dst.(type) {
+	case *int:
+		*p, err = strconv.Atoi(s)
+	case *int32:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 32)
+		*p = int32(i)
+	case *int64:
+		*p, err = strconv.ParseInt(s, 10, 64)
+	case *xql.Decimal:
+		*p, err = xql.ParseDecimal(s)
+	case *xql.Numeric:
+		var d xql.Decimal
+		d, err = xql.ParseDecimal(s)
+		*p = xql.Numeric(d)
+	case *time.Time:
+		for _, layout := range boundTimeLayouts {
+			var t time.Time
+			if t, err = time.Parse(layout, s); nil == err {
+				*p = t
+				break
+			}
+		}
+	case sql.Scanner:
+		err = p.Scan(s)
+	default:
+		err = fmt.Errorf("unsupported range bound type: %T", dst)
+	}
+	return err
+}
+
+// RangeContains
+// Which makes a filter of range column field containing element v: field @> [v,v].
+func RangeContains[T any](field string, v T) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "@>", Value: NewRange(v, v, "[]")}
+}
+
+// RangeContainsRange
+// Which makes a filter of range column field containing r: field @> r.
+func RangeContainsRange[T any](field string, r Range[T]) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "@>", Value: r}
+}
+
+// RangeContainedBy
+// Which makes a filter of range column field contained by r: field <@ r.
+func RangeContainedBy[T any](field string, r Range[T]) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "<@", Value: r}
+}
+
+// RangeOverlaps
+// Which makes a filter of range column field overlapping r: field && r.
+func RangeOverlaps[T any](field string, r Range[T]) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "&&", Value: r}
+}
+
+// RangeAdjacent
+// Which makes a filter of range column field adjacent to r: field -|- r.
+func RangeAdjacent[T any](field string, r Range[T]) xql.QueryFilter {
+	return xql.QueryFilter{Field: field, Operator: "-|-", Value: r}
+}
+
+// ExcludeOverlap
+// Which makes the statement of an EXCLUDE constraint, rejecting rows with equal columns
+// and overlapping range column, for TableConstrained:
+//
+//	{"exclude", "room,during", postgres.ExcludeOverlap("during", "room")}
+//
+// is the same as tag `xql:"exclude=room"` on column during, and makes:
+//
+//	EXCLUDE USING gist (room WITH =, during WITH &&)
+//
+// Extension btree_gist is required for equality of scalar columns.
+func ExcludeOverlap(rangeColumn string, equals ...string) string {
+	var parts []string
+	for _, c := range equals {
+		parts = append(parts, escapePGkw(c)+" WITH =")
+	}
+	parts = append(parts, escapePGkw(rangeColumn)+" WITH &&")
+	return "gist (" + strings.Join(parts, ", ") + ")"
+}
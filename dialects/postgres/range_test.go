@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+// label
+// A string bound scanned and valued by itself.
+type label string
+
+func (l label) Value() (driver.Value, error) { return string(l), nil }
+
+func (l *label) Scan(src interface{}) error {
+	*l = label(fmt.Sprint(src))
+	return nil
+}
+
+func TestRangeRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		r    Range[int]
+		text string
+	}{
+		{"bounded", NewRange(1, 10), `["1","10")`},
+		{"inclusive", NewRange(-5, 5, "(]"), `("-5","5"]`},
+		{"empty", EmptyRange[int](), `empty`},
+		{"lower unbounded", Range[int]{Upper: 3, LowerInf: true, Valid: true}, `(,"3")`},
+		{"upper unbounded", Range[int]{Lower: 3, LowerInc: true, UpperInf: true, Valid: true}, `["3",)`},
+		{"unbounded", Range[int]{LowerInf: true, UpperInf: true, Valid: true}, `(,)`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := c.r.Value()
+			if nil != err {
+				t.Fatal(err)
+			}
+			if v != c.text {
+				t.Errorf("Value() = %v, want %s", v, c.text)
+			}
+			var r Range[int]
+			if err := r.Scan([]byte(c.text)); nil != err {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(r, c.r) {
+				t.Errorf("Scan(%s) = %+v, want %+v", c.text, r, c.r)
+			}
+		})
+	}
+}
+
+func TestRangeNull(t *testing.T) {
+	var r Range[int]
+	if v, err := r.Value(); nil != err || nil != v {
+		t.Errorf("zero Value() = %v, %v, want NULL", v, err)
+	}
+	r = NewRange(1, 2)
+	if err := r.Scan(nil); nil != err {
+		t.Fatal(err)
+	}
+	if r != (Range[int]{}) {
+		t.Errorf("Scan(NULL) = %+v, want zero", r)
+	}
+	if v, _ := EmptyRange[int]().Value(); v != "empty" {
+		t.Errorf("empty Value() = %v", v)
+	}
+	// Ranges of zero bounds are not NULL.
+	if v, _ := NewRange(0, 0, "()").Value(); v != `("0","0")` {
+		t.Errorf("zero bounds Value() = %v", v)
+	}
+	if v, _ := (Range[int]{Valid: true}).Value(); v != `("0","0")` {
+		t.Errorf("valid zero Value() = %v", v)
+	}
+}
+
+func TestRangeScan(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		text string
+		want Range[time.Time]
+	}{
+		{`[2024-01-02,2024-01-03)`, NewRange(day, day.AddDate(0, 0, 1))},
+		{`["2024-01-02 00:00:00+00","2024-01-03 00:00:00+00"]`, NewRange(day, day.AddDate(0, 0, 1), "[]")},
+		{`[-infinity,2024-01-02)`, Range[time.Time]{LowerInf: true, Upper: day, Valid: true}},
+		{`["2024-01-02",infinity]`, Range[time.Time]{Lower: day, LowerInc: true, UpperInf: true, Valid: true}},
+		{`(-infinity,infinity)`, Range[time.Time]{LowerInf: true, UpperInf: true, Valid: true}},
+		{`EMPTY`, EmptyRange[time.Time]()},
+	}
+	for _, c := range cases {
+		var r Range[time.Time]
+		if err := r.Scan(c.text); nil != err {
+			t.Errorf("Scan(%s): %v", c.text, err)
+			continue
+		}
+		if !r.Lower.Equal(c.want.Lower) || !r.Upper.Equal(c.want.Upper) {
+			t.Errorf("Scan(%s) = %v, want %v", c.text, r, c.want)
+		}
+		r.Lower, r.Upper, c.want.Lower, c.want.Upper = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+		if r != c.want {
+			t.Errorf("Scan(%s) = %+v, want %+v", c.text, r, c.want)
+		}
+	}
+	for _, bad := range []string{`[1,2`, `1,2)`, `[1,2,3)`, `["1,2)`, `[a,2)`} {
+		var r Range[int]
+		if err := r.Scan(bad); nil == err {
+			t.Errorf("Scan(%s) = %+v, want error", bad, r)
+		}
+	}
+}
+
+func TestRangeQuoting(t *testing.T) {
+	cases := []struct {
+		r    Range[label]
+		text string
+	}{
+		{NewRange[label]("a,b", "c d"), `["a,b","c d")`},
+		{NewRange[label](`say "hi"`, `back\slash`), `["say \"hi\"","back\\slash")`},
+		{NewRange[label]("", "(]"), `["","(]")`},
+	}
+	for _, c := range cases {
+		v, _ := c.r.Value()
+		if v != c.text {
+			t.Errorf("Value() = %v, want %s", v, c.text)
+		}
+		var r Range[label]
+		if err := r.Scan(c.text); nil != err {
+			t.Fatal(err)
+		}
+		if r != c.r {
+			t.Errorf("Scan(%s) = %+v, want %+v", c.text, r, c.r)
+		}
+	}
+	// Doubled quotes and unquoted escapes, as PostgreSQL may return them.
+	var r Range[label]
+	if err := r.Scan(`["say ""hi""",a\,b]`); nil != err {
+		t.Fatal(err)
+	}
+	if r.Lower != `say "hi"` || r.Upper != "a,b" || !r.UpperInc {
+		t.Errorf("Scan = %+v", r)
+	}
+}
+
+type badSpan struct {
+	Id   int `xql:"type=serial,pk"`
+	Span Range[string]
+}
+
+func (b badSpan) TableName() string { return "bad_spans" }
+
+func TestRangeDeclareError(t *testing.T) {
+	if s := (Range[string]{}).Declare(nil); s != "" {
+		t.Errorf("Declare() = %q, want empty", s)
+	}
+	if _, err := xql.TryDeclareTable(badSpan{}); nil == err || !strings.Contains(err.Error(), "range type of string") {
+		t.Errorf("TryDeclareTable() error = %v", err)
+	}
+}
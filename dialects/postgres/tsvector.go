@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/archsh/go.xql"
+)
+
+const defaultTSConfig = "english"
+
+// TSVector
+// Full text search document. With tag 'tsvector' the column is generated from
+// the listed source columns and weights, for example:
+//
+//	Search postgres.TSVector `xql:"tsvector=name:A;desc:B,tsconfig=english,index=gin"`
+type TSVector string
+
+func (v TSVector) Declare(props xql.PropertySet) string {
+	sources, ok := props.GetString("tsvector")
+	if !ok || sources == "" {
+		return "tsvector"
+	}
+	config, _ := props.GetString("tsconfig", defaultTSConfig)
+	var parts []string
+	for _, src := range strings.Split(sources, ";") {
+		if src == "" {
+			continue
+		}
+		ss := strings.SplitN(src, ":", 2)
+		part := fmt.Sprintf("to_tsvector(%s, coalesce(%s, ''))", pq.QuoteLiteral(config), escapePGkw(ss[0]))
+		if len(ss) > 1 && ss[1] != "" {
+			part = fmt.Sprintf("setweight(%s, %s)", part, pq.QuoteLiteral(strings.ToUpper(ss[1])))
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("tsvector GENERATED ALWAYS AS (%s) STORED", strings.Join(parts, " || "))
+}
+
+func (v TSVector) Generated(props xql.PropertySet) bool {
+	sources, ok := props.GetString("tsvector")
+	return ok && sources != ""
+}
+
+func (v *TSVector) Scan(value interface{}) error {
+	var s sql.NullString
+	if err := s.Scan(value); err != nil {
+		return err
+	}
+	*v = TSVector(s.String)
+	return nil
+}
+
+func (v TSVector) Value() (driver.Value, error) {
+	if v == "" {
+		return nil, nil
+	}
+	return string(v), nil
+}
+
+// TSQueryMode
+// Which decides the function used to parse a full text search query.
+type TSQueryMode uint8
+
+const (
+	TSPlain     TSQueryMode = iota // plainto_tsquery
+	TSPhrase                       // phraseto_tsquery
+	TSWebSearch                    // websearch_to_tsquery
+	TSRaw                          // to_tsquery
+)
+
+func (m TSQueryMode) function() string {
+	switch m {
+	case TSPhrase:
+		return "phraseto_tsquery"
+	case TSWebSearch:
+		return "websearch_to_tsquery"
+	case TSRaw:
+		return "to_tsquery"
+	default:
+		return "plainto_tsquery"
+	}
+}
+
+// TSQuery
+// Which returns the tsquery expression of given query, config defaults to english.
+// The query is inlined as a literal, Match and Rank pass it as a parameter instead.
+func TSQuery(query string, mode TSQueryMode, config ...string) string {
+	return fmt.Sprintf("%s(%s, %s)", mode.function(), pq.QuoteLiteral(tsConfig(config...)), pq.QuoteLiteral(query))
+}
+
+func tsConfig(config ...string) string {
+	if len(config) > 0 && config[0] != "" {
+		return config[0]
+	}
+	return defaultTSConfig
+}
+
+// ToTSVector
+// Which returns the tsvector expression of a text column, to match columns which are not TSVector.
+func ToTSVector(field string, config ...string) string {
+	return fmt.Sprintf("to_tsvector(%s, %s)", pq.QuoteLiteral(tsConfig(config...)), escapePGkw(field))
+}
+
+// Match
+// Which returns a filter of 'field @@ query', field is a TSVector column or a ToTSVector expression.
+// The query is passed as a parameter.
+//
+//	session.Table(StudentTable).Filter(postgres.Match("search", "tom", postgres.TSWebSearch))
+func Match(field string, query string, mode TSQueryMode, config ...string) xql.QueryFilter {
+	return xql.QueryFilter{
+		Field: fmt.Sprintf("%s @@ %s(%s, $1)", escapePGkw(field), mode.function(), pq.QuoteLiteral(tsConfig(config...))),
+		Args:  []interface{}{query},
+	}
+}
+
+// Rank
+// Which returns an order by ts_rank of field against query, best matches first.
+// The query is passed as a parameter.
+func Rank(field string, query string, mode TSQueryMode, config ...string) xql.QueryOrder {
+	return xql.QueryOrder{
+		Type:  xql.OrderDesc,
+		Field: fmt.Sprintf("ts_rank(%s, %s(%s, $1))", escapePGkw(field), mode.function(), pq.QuoteLiteral(tsConfig(config...))),
+		Args:  []interface{}{query},
+	}
+}
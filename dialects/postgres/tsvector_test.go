@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+func TestBindRaw(t *testing.T) {
+	s, n, args := bindRaw(`a = $1 AND b <> '$1' AND c IN ($2, 'it''s $2')`, []interface{}{"x", "y"}, 2, []interface{}{1, 2})
+	if s != `a = $3 AND b <> '$1' AND c IN ($4, 'it''s $2')` || n != 4 {
+		t.Errorf("bindRaw() = %s, %d", s, n)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 2, "x", "y"}) {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestMatchAndRank(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter xql.QueryFilter
+		order  xql.QueryOrder
+		want   string
+	}{
+		{"plain", Match("search", "tom's", TSPlain), Rank("search", "tom's", TSPlain),
+			`SELECT "id" FROM members WHERE "name" = $1 AND "search" @@ plainto_tsquery('english', $2) ORDER BY ts_rank("search", plainto_tsquery('english', $3)) DESC`},
+		{"web simple", Match(ToTSVector("name", "simple"), "a -b", TSWebSearch, "simple"), Rank("search", "a", TSPhrase, "simple"),
+			`SELECT "id" FROM members WHERE "name" = $1 AND to_tsvector('simple', "name") @@ websearch_to_tsquery('simple', $2) ORDER BY ts_rank("search", phraseto_tsquery('simple', $3)) DESC`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			filters := []xql.QueryFilter{{Field: "name", Operator: "=", Value: "n"}, c.filter}
+			s, args, err := postgresDialect{}.Select(memberTable, []xql.QueryColumn{{FieldName: "id"}}, filters, []xql.QueryOrder{c.order}, "", -1, -1)
+			if nil != err {
+				t.Fatal(err)
+			}
+			if s != c.want {
+				t.Errorf("got  %s\nwant %s", s, c.want)
+			}
+			if len(args) != 3 || args[1] != c.filter.Args[0] || args[2] != c.order.Args[0] {
+				t.Errorf("args = %v", args)
+			}
+		})
+	}
+}
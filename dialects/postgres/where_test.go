@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+func TestMakeWhere(t *testing.T) {
+	cases := []struct {
+		name    string
+		filters []xql.QueryFilter
+		where   string
+		args    []interface{}
+	}{
+		{"empty", nil, "", nil},
+		{"equal", []xql.QueryFilter{{Field: "id", Operator: "=", Value: 1}}, ` WHERE "id" = $1`, []interface{}{1}},
+		{"and or", []xql.QueryFilter{
+			{Field: "age", Operator: ">", Value: 18},
+			{Field: "name", Operator: "LIKE", Value: "a%", Condition: xql.ConditionOr},
+		}, ` WHERE age > $1 OR "name" LIKE $2`, []interface{}{18, "a%"}},
+		{"raw", []xql.QueryFilter{{Field: "deleted IS NULL"}}, ` WHERE deleted IS NULL`, nil},
+		{"function", []xql.QueryFilter{{Field: "name", Operator: "=", Function: "lower", Value: "Tom"}}, ` WHERE "name" = lower($1)`, []interface{}{"Tom"}},
+		{"reversed", []xql.QueryFilter{{Field: "ANY(tags)", Operator: "=", Value: "x", Reversed: true}}, ` WHERE $1 = ANY(tags)`, []interface{}{"x"}},
+		{"in", []xql.QueryFilter{{Field: "id", Operator: "IN", Value: []int{1, 2, 3}}}, ` WHERE "id" IN ($1,$2,$3)`, []interface{}{1, 2, 3}},
+		{"not in", []xql.QueryFilter{{Field: "id", Operator: "not in", Value: []string{"a"}}}, ` WHERE "id" not in ($1)`, []interface{}{"a"}},
+		{"empty in", []xql.QueryFilter{{Field: "id", Operator: "IN", Value: []int{}}}, ` WHERE FALSE`, nil},
+		{"empty not in", []xql.QueryFilter{{Field: "id", Operator: "NOT IN", Value: []int{}}}, ` WHERE TRUE`, nil},
+		{"bytes not expanded", []xql.QueryFilter{{Field: "data", Operator: "IN", Value: []byte("ab")}}, ` WHERE "data" IN $1`, []interface{}{[]byte("ab")}},
+	}
+	for _, c := range cases {
+		where, _, args := makeWhere(c.filters, 0, nil)
+		if where != c.where || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("%s: got %q %v, want %q %v", c.name, where, args, c.where, c.args)
+		}
+	}
+}
+
+func TestMakeWhereOffset(t *testing.T) {
+	where, n, args := makeWhere([]xql.QueryFilter{{Field: "id", Operator: "IN", Value: []int{7, 8}}}, 2, []interface{}{"a", "b"})
+	if where != ` WHERE "id" IN ($3,$4)` || n != 4 || len(args) != 4 {
+		t.Errorf("got %q %d %v", where, n, args)
+	}
+}
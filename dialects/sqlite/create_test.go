@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"strings"
+	"testing"
+
+	xql "github.com/archsh/go.xql"
+)
+
+type mood string
+
+func (m mood) EnumValues() []string { return []string{"sad", "it's ok", "happy"} }
+
+type diary struct {
+	Id    int `xql:"type=integer,pk"`
+	Mood  mood
+	Shelf string `xql:"enum=top;low,nullable=true"`
+	Title string `xql:"type=text"`
+}
+
+func (d diary) TableName() string { return "diaries" }
+
+func TestCreate(t *testing.T) {
+	s, _, err := sqliteDialect{}.Create(xql.DeclareTable(diary{}))
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS diaries ( ",
+		"id integer NOT NULL PRIMARY KEY",
+		"mood TEXT NOT NULL CHECK (mood IN ('sad','it''s ok','happy'))",
+		"shelf TEXT CHECK (shelf IN ('top','low'))",
+		"title text NOT NULL",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("%q not found in:\n%s", want, s)
+		}
+	}
+	if strings.Contains(s, "ENUM") || strings.Contains(s, "diaries_mood ") {
+		t.Errorf("enum type declared in:\n%s", s)
+	}
+}
@@ -1,15 +1,49 @@
 package sqlite
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+
 	xql "github.com/archsh/go.xql"
 )
 
 type sqliteDialect struct{}
 
+// Create
+// Implement the IDialect interface for creating table, with columns only. Enum
+// columns are declared as TEXT with a CHECK of their values, sqlite has no enum types.
 func (s sqliteDialect) Create(table *xql.Table, i ...interface{}) (stm string, args []interface{}, err error) {
-	//TODO implement me
-	panic("implement me")
+	var cols []string
+	for _, c := range table.GetColumns() {
+		cols = append(cols, makeColumn(c))
+	}
+	stm = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ( %s );", table.TableName(), strings.Join(cols, ", "))
+	return
+}
+
+// makeColumn
+// Which makes the definition of column c in CREATE TABLE.
+func makeColumn(c *xql.Column) string {
+	parts := []string{c.FieldName}
+	if c.EnumType != "" {
+		parts = append(parts, "TEXT")
+	} else {
+		parts = append(parts, c.TypeFor("sqlite"))
+	}
+	if c.Default != nil {
+		parts = append(parts, fmt.Sprintf("DEFAULT %v", c.Default))
+	}
+	if !c.Nullable {
+		parts = append(parts, "NOT NULL")
+	}
+	if c.PrimaryKey {
+		parts = append(parts, "PRIMARY KEY")
+	}
+	if check := c.EnumCheck(); check != "" {
+		parts = append(parts, fmt.Sprintf("CHECK (%s)", check))
+	}
+	return strings.Join(parts, " ")
 }
 
 func (s sqliteDialect) Drop(table *xql.Table, b bool) (stm string, args []interface{}, err error) {
@@ -44,6 +78,10 @@ func (s sqliteDialect) Delete(table *xql.Table, filters []xql.QueryFilter) (stm
 	panic("implement me")
 }
 
+func (s sqliteDialect) LockFor(lock xql.QueryLock) (string, error) {
+	return "", errors.New("row locking is not supported by sqlite")
+}
+
 func init() {
 	xql.RegisterDialect("sqlite", &sqliteDialect{})
 }
@@ -0,0 +1,69 @@
+package xql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Enumerable
+// Which column type implemented returns the values allowed, the column is declared
+// as an enum of them, like tag 'enum=sad;ok;happy'.
+type Enumerable interface {
+	EnumValues() []string
+}
+
+// EnumValues
+// Which returns values allowed by tag 'enum=a;b;c' or the Enumerable type of column.
+func (c Column) EnumValues() []string {
+	s, ok := c.GetString("enum")
+	if !ok || s == "" {
+		return nil
+	}
+	var values []string
+	for _, x := range strings.Split(s, ";") {
+		values = append(values, strings.Trim(strings.TrimSpace(x), "'"))
+	}
+	return values
+}
+
+// EnumCheck
+// Which returns a CHECK expression of enum values, for dialects without enum types,
+// empty if the column has no enum values.
+func (c Column) EnumCheck() string {
+	if len(c.EnumValues()) < 1 {
+		return ""
+	}
+	var values []string
+	for _, x := range c.EnumValues() {
+		values = append(values, "'"+strings.ReplaceAll(x, "'", "''")+"'")
+	}
+	return fmt.Sprintf("%s IN (%s)", c.FieldName, strings.Join(values, ","))
+}
+
+// makeEnumType
+// Which returns the enum type name of column, empty if it has no enum values or the
+// type is given by tag. Values of an Enumerable type are kept in tag 'enum'. Names
+// made of table and column are in the schema of table.
+func makeEnumType(t *Table, c *Column, v reflect.Value) string {
+	if _, ok := c.GetString("enum"); !ok {
+		if e, ok := v.Interface().(Enumerable); ok {
+			c.PropertySet["enum"] = strings.Join(e.EnumValues(), ";")
+		}
+	}
+	if len(c.EnumValues()) < 1 {
+		return ""
+	}
+	if _, ok := c.GetString("type"); ok {
+		return ""
+	}
+	name, ok := c.GetString("enumtype")
+	if !ok || name == "" {
+		name = t.BaseTableName() + "_" + c.FieldName
+		if t.schema != "" {
+			name = t.schema + "." + name
+		}
+		c.PropertySet["enumtype"] = name
+	}
+	return name
+}
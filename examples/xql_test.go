@@ -54,7 +54,7 @@ type People struct {
 	SchoolId    int        `json:"schoolId"  xql:"type=integer,fk=schools.id,ondelete=CASCADE"`
 	Description string     `json:"description"  xql:"name=desc,type=text,size=24,default=''"`
 	Created     *time.Time `json:"created"  xql:"type=timestamp,default=Now()"`
-	Updated     *time.Time `json:"Updated"  xql:"type=timestamp,default=Now()"`
+	Updated     *time.Time `json:"Updated"  xql:"type=timestamp,default=Now(),autoupdate"`
 }
 
 type Teacher struct {
@@ -238,6 +238,43 @@ func TestQuerySet_Scan(t *testing.T) {
 	t.Log("Time spent:> ", time.Now().Sub(t1))
 }
 
+func TestQuery_All(t *testing.T) {
+	t1 := time.Now()
+	students, e := xql.From[Student](session, StudentTable).Where("region", "US").OrderBy("id").All()
+	if nil != e {
+		t.Fatal("Query all failed:>", e)
+	}
+	for _, c := range students {
+		t.Log("Queried Student:> Id:", c.Id, c.FullName)
+	}
+	if len(students) > 0 {
+		if c, e := xql.From[Student](session, StudentTable).Get(students[0].Id); nil != e {
+			t.Fatal("Query get failed:>", e)
+		} else if c.FullName != students[0].FullName {
+			t.Fatal("Query get mismatch:>", c.FullName, students[0].FullName)
+		}
+	}
+	t.Log("Time spent:> ", time.Now().Sub(t1))
+}
+
+func TestQuery_Iter(t *testing.T) {
+	t1 := time.Now()
+	for c, e := range xql.Iterate[Student](session.Table(StudentTable).All()) {
+		if nil != e {
+			t.Fatal("Iterate failed:>", e)
+		}
+		t.Log("Iterated Student:> Id:", c.Id, c.FullName)
+	}
+	for id, e := range xql.Iterate[int](session.QueryRows("SELECT id FROM students")) {
+		if nil != e {
+			t.Fatal("Iterate raw failed:>", e)
+		}
+		t.Log("Iterated Student:> Id:", id)
+		break
+	}
+	t.Log("Time spent:> ", time.Now().Sub(t1))
+}
+
 func TestQuerySet_Update(t *testing.T) {
 	t1 := time.Now()
 	var ids []int
@@ -1,6 +1,7 @@
 package xql
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"time"
 )
@@ -31,15 +32,20 @@ func (s BigInteger) Declare(props PropertySet) string {
 }
 
 // Decimal	variable	user-specified precision, exact	up to 131072 digits before the decimal point; up to 16383 digits after the decimal point
+// Declared as decimal(p,s) with tag 'precision=p,scale=s', see decimal.go for values.
 type Decimal string
 
 func (d Decimal) Declare(props PropertySet) string {
-	return "decimal"
+	return declareNumeric("decimal", props)
 }
 
 // Numeric	variable	user-specified precision, exact	up to 131072 digits before the decimal point; up to 16383 digits after the decimal point
 type Numeric Decimal
 
+func (n Numeric) Declare(props PropertySet) string {
+	return declareNumeric("numeric", props)
+}
+
 // Real	4 bytes	variable-precision, inexact	6 decimal digits precision
 type Real float32
 
@@ -51,7 +57,7 @@ func (s Real) Declare(props PropertySet) string {
 type Double float64
 
 func (s Double) Declare(props PropertySet) string {
-	return "double"
+	return "double precision"
 }
 
 // SmallSerial	2 bytes	small autoincrementing integer	1 to 32767
@@ -151,12 +157,46 @@ func (s Time) Declare(props PropertySet) string {
 }
 
 // Interval [ columns ] [ (p) ]	16 bytes	time interval	-178000000 years	178000000 years	1 microsecond / 14 digits
+// Scanned from interval text of all IntervalStyles, a month is 30 days and a day is 24 hours.
+// Use postgres.Interval to keep months and days apart.
 type Interval time.Duration
 
 func (s Interval) Declare(props PropertySet) string {
 	return "interval"
 }
 
+func (s Interval) String() string {
+	return time.Duration(s).String()
+}
+
+// Scan implements the sql.Scanner interface, NULL makes a zero Interval.
+func (s *Interval) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		*s = 0
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("converting %T to Interval is unsupported", value)
+	}
+	months, days, us, err := ParseInterval(text)
+	if nil != err {
+		return err
+	}
+	d := time.Duration(int64(months)*30+int64(days))*24*time.Hour + time.Duration(us)*time.Microsecond
+	*s = Interval(d)
+	return nil
+}
+
+// Value implements the driver Valuer interface, in ISO 8601 format like PT1H2M3S.
+func (s Interval) Value() (driver.Value, error) {
+	return FormatInterval(0, 0, time.Duration(s).Microseconds()), nil
+}
+
 // Boolean Data Type
 //
 // Name	Storage Size	Description
@@ -172,10 +212,15 @@ func (s Boolean) Declare(props PropertySet) string {
 //Enum types are created using the CREATE TYPE command, for example:
 
 // Enum CREATE TYPE mood AS ENUM ('sad', 'ok', 'happy');
+// Values are given by tag 'enum=sad;ok;happy', the type is named by tag 'enumtype'
+// or <table>_<column> by default.
 type Enum string
 
 func (s Enum) Declare(props PropertySet) string {
-	return "boolean"
+	if name, ok := props.GetString("enumtype"); ok && name != "" {
+		return name
+	}
+	return Varchar("").Declare(props)
 }
 
 // UUID Type
@@ -1,5 +1,5 @@
 module github.com/archsh/go.xql
 
-go 1.20
+go 1.23
 
 require github.com/lib/pq v1.10.9
@@ -0,0 +1,63 @@
+package xql_test
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/archsh/go.xql"
+	_ "github.com/archsh/go.xql/dialects/postgres"
+	"github.com/archsh/go.xql/internal/fakedb"
+)
+
+// openSession
+// Which returns a postgres session on a recording fake database.
+func openSession(t *testing.T) (*xql.Session, *fakedb.DB) {
+	t.Helper()
+	db, fdb := fakedb.Open()
+	t.Cleanup(func() { _ = db.Close() })
+	return xql.MakeSession(db, "postgres"), fdb
+}
+
+// lastSQL
+// Which returns the last statement received, failing the test if none.
+func lastSQL(t *testing.T, fdb *fakedb.DB) string {
+	t.Helper()
+	s := fdb.Last()
+	if s.SQL == "" {
+		t.Fatal("no statement received")
+	}
+	return s.SQL
+}
+
+// rows
+// Which returns a result of the comma separated columns and values.
+func rows(cols string, values ...[]driver.Value) fakedb.Result {
+	return fakedb.Result{Columns: strings.Split(cols, ","), Rows: values}
+}
+
+// assertStatement
+// Which checks the statement ends with where and received args.
+func assertStatement(t *testing.T, s fakedb.Statement, where string, args ...interface{}) {
+	t.Helper()
+	if !strings.HasSuffix(s.SQL, where) {
+		t.Errorf("got  %s\nwant ... %s", s.SQL, where)
+	}
+	var want []driver.Value
+	for _, a := range args {
+		want = append(want, a)
+	}
+	if !reflect.DeepEqual(s.Args, want) {
+		t.Errorf("args = %v, want %v", s.Args, want)
+	}
+}
+
+type Book struct {
+	Id    int    `xql:"type=serial,pk"`
+	Title string `xql:"size=64"`
+}
+
+func (b Book) TableName() string { return "books" }
+
+var BookTable = xql.DeclareTable(Book{})
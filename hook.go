@@ -0,0 +1,192 @@
+package xql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OperationType
+// Which tells the kind of a write Operation, OperationRead is used by permission checks only.
+type OperationType uint8
+
+const (
+	OperationInsert OperationType = iota + 1
+	OperationUpdate
+	OperationDelete
+	OperationRead
+)
+
+func (t OperationType) String() string {
+	switch t {
+	case OperationInsert:
+		return "insert"
+	case OperationUpdate:
+		return "update"
+	case OperationDelete:
+		return "delete"
+	case OperationRead:
+		return "read"
+	}
+	return fmt.Sprintf("OperationType(%d)", uint8(t))
+}
+
+// Operation
+// A write operation on table, passed to interceptors and available to entity hooks
+// with Session.Operation. Filters and Columns may be changed before execution.
+//
+// Operations on filters (updates with a map or []UpdateColumn, deletes of rows matched,
+// HardDelete and Restore) have no Entity, hooks are called on a new zero value of the
+// entity type given to DeclareTable for them.
+type Operation struct {
+	Type         OperationType
+	Table        *Table
+	Entity       interface{}    // Entity written, nil for operations on filters
+	Filters      []QueryFilter  // Filters of update and delete
+	Columns      []UpdateColumn // Columns of update
+	RowsAffected int64          // Rows affected, available after execution
+}
+
+// Interceptor
+// Which runs around every write operation, registered on Engine, Session or Table.
+// An error returned aborts the operation and is returned to the caller, as errors of
+// entity hooks and of the statement are. The transaction if any is left open, the
+// caller rolls it back.
+type Interceptor interface {
+	Before(*Session, *Operation) error
+	After(*Session, *Operation) error
+}
+
+// AddInterceptor
+// Which registers interceptors for all tables of sessions made by the engine.
+func (engine *Engine) AddInterceptor(interceptors ...Interceptor) {
+	engine.interceptors = append(engine.interceptors, interceptors...)
+}
+
+// AddInterceptor
+// Which registers interceptors for all tables written by the session.
+func (session *Session) AddInterceptor(interceptors ...Interceptor) {
+	session.interceptors = append(session.interceptors, interceptors...)
+}
+
+// AddInterceptor
+// Which registers interceptors for the table.
+func (t *Table) AddInterceptor(interceptors ...Interceptor) *Table {
+	t.interceptors = append(t.interceptors, interceptors...)
+	return t
+}
+
+// Operation
+// Which returns the write operation running, for entity hooks to inspect. It is set
+// on the session passed to hooks and interceptors only, which is a copy of the session
+// running the operation sharing its database and transaction.
+func (session *Session) Operation() *Operation {
+	return session.op
+}
+
+// withOperation
+// Which returns a copy of session running op, passed to hooks and interceptors.
+func (session *Session) withOperation(op *Operation) *Session {
+	s := *session
+	s.op = op
+	return &s
+}
+
+// hookEntity
+// Which returns the entity of op to call hooks on, a new zero value of the table entity
+// type for operations without entity, so hooks never change the declared entity.
+func (qs QuerySet) hookEntity(op *Operation) interface{} {
+	if nil != op.Entity {
+		return op.Entity
+	}
+	t := reflect.TypeOf(qs.table.entity)
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface()
+	}
+	return reflect.New(t).Elem().Interface()
+}
+
+// before
+// Which calls the Pre hook of entity and Before of interceptors.
+func (qs QuerySet) before(session *Session, op *Operation, entity interface{}) error {
+	var err error
+	switch op.Type {
+	case OperationInsert:
+		if h, ok := entity.(TablePreInsert); ok {
+			err = h.PreInsert(qs.table, session)
+		}
+	case OperationUpdate:
+		if h, ok := entity.(TablePreUpdate); ok {
+			err = h.PreUpdate(qs.table, session)
+		}
+	case OperationDelete:
+		if h, ok := entity.(TablePreDelete); ok {
+			err = h.PreDelete(qs.table, session)
+		}
+	}
+	if nil != err {
+		return err
+	}
+	for _, x := range qs.interceptors() {
+		if err := x.Before(session, op); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// after
+// Which calls the Post hook of entity and After of interceptors.
+func (qs QuerySet) after(session *Session, op *Operation, entity interface{}) error {
+	var err error
+	switch op.Type {
+	case OperationInsert:
+		if h, ok := entity.(TablePostInsert); ok {
+			err = h.PostInsert(qs.table, session)
+		}
+	case OperationUpdate:
+		if h, ok := entity.(TablePostUpdate); ok {
+			err = h.PostUpdate(qs.table, session)
+		}
+	case OperationDelete:
+		if h, ok := entity.(TablePostDelete); ok {
+			err = h.PostDelete(qs.table, session)
+		}
+	}
+	if nil != err {
+		return err
+	}
+	for _, x := range qs.interceptors() {
+		if err := x.After(session, op); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (qs QuerySet) interceptors() []Interceptor {
+	return append(qs.session.interceptors[:len(qs.session.interceptors):len(qs.session.interceptors)],
+		qs.table.interceptors...)
+}
+
+// run
+// Which executes op with fn, hooks and interceptors around it. An error of any
+// of them aborts the operation and is returned, the transaction is left to the caller.
+func (qs QuerySet) run(op *Operation, fn func(*Operation) (int64, error)) (int64, error) {
+	if err := qs.permit(op.Type); nil != err {
+		return 0, err
+	}
+	session := qs.session.withOperation(op)
+	entity := qs.hookEntity(op)
+	if err := qs.before(session, op, entity); nil != err {
+		return 0, err
+	}
+	n, err := fn(op)
+	if nil != err {
+		return n, err
+	}
+	op.RowsAffected = n
+	if err := qs.after(session, op, entity); nil != err {
+		return n, err
+	}
+	return n, nil
+}
@@ -0,0 +1,203 @@
+package xql_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+type Gadget struct {
+	Id   int    `xql:"type=serial,pk"`
+	Name string `xql:"size=32"`
+}
+
+func (g Gadget) TableName() string { return "gadgets" }
+
+var (
+	gadgetOps   []*xql.Operation
+	gadgetMutex sync.Mutex
+	gadgetError error
+)
+
+func (g Gadget) PreUpdate(t *xql.Table, s *xql.Session) error {
+	gadgetMutex.Lock()
+	defer gadgetMutex.Unlock()
+	gadgetOps = append(gadgetOps, s.Operation())
+	return gadgetError
+}
+
+var GadgetTable = xql.DeclareTable(Gadget{})
+
+type recorder struct {
+	before, after []xql.OperationType
+	err           error
+}
+
+func (r *recorder) Before(s *xql.Session, op *xql.Operation) error {
+	r.before = append(r.before, op.Type)
+	return r.err
+}
+
+func (r *recorder) After(s *xql.Session, op *xql.Operation) error {
+	r.after = append(r.after, op.Type)
+	return nil
+}
+
+func TestHooksOperation(t *testing.T) {
+	session, _ := openSession(t)
+	gadgetOps, gadgetError = nil, nil
+	if _, err := session.Table(GadgetTable).Where("id", 1).Update(map[string]interface{}{"name": "x"}); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := session.Table(GadgetTable).Update(Gadget{Id: 1, Name: "y"}); nil != err {
+		t.Fatal(err)
+	}
+	if len(gadgetOps) != 2 || nil == gadgetOps[0] || nil == gadgetOps[1] {
+		t.Fatalf("operations = %v", gadgetOps)
+	}
+	if nil != gadgetOps[0].Entity || gadgetOps[0].Type != xql.OperationUpdate || len(gadgetOps[0].Columns) != 1 {
+		t.Errorf("filter operation = %+v", gadgetOps[0])
+	}
+	if g, ok := gadgetOps[1].Entity.(Gadget); !ok || g.Name != "y" {
+		t.Errorf("entity operation = %+v", gadgetOps[1])
+	}
+	if nil != session.Operation() {
+		t.Errorf("operation left on session: %+v", session.Operation())
+	}
+}
+
+func TestHooksConcurrent(t *testing.T) {
+	session, _ := openSession(t)
+	gadgetOps, gadgetError = nil, nil
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = session.Table(GadgetTable).Update(Gadget{Id: i, Name: "x"})
+		}(i)
+	}
+	wg.Wait()
+	seen := make(map[int]bool)
+	for _, op := range gadgetOps {
+		seen[op.Entity.(Gadget).Id] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("operations seen by hooks = %v", seen)
+	}
+}
+
+func TestAbortKeepsTransaction(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(*xql.Session, *recorder)
+	}{
+		{"statement", func(s *xql.Session, r *recorder) {}},
+		{"interceptor", func(s *xql.Session, r *recorder) { r.err = errors.New("denied") }},
+		{"hook", func(s *xql.Session, r *recorder) { gadgetError = errors.New("invalid") }},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			session, fdb := openSession(t)
+			gadgetError = nil
+			defer func() { gadgetError = nil }()
+			r := &recorder{}
+			session.AddInterceptor(r)
+			c.setup(session, r)
+			if c.name == "statement" {
+				fdb.Fail = func(q string) error {
+					if q == "BEGIN" || q == "ROLLBACK" {
+						return nil
+					}
+					return errors.New("deadlock detected")
+				}
+			}
+			if err := session.Begin(); nil != err {
+				t.Fatal(err)
+			}
+			if _, err := session.Table(GadgetTable).Where("id", 1).Update(map[string]interface{}{"name": "x"}); nil == err {
+				t.Fatal("expected error")
+			}
+			// The transaction is left to the caller.
+			if s := lastSQL(t, fdb); s == "ROLLBACK" {
+				t.Errorf("rolled back on error")
+			}
+			if err := session.Rollback(); nil != err {
+				t.Errorf("Rollback() = %v", err)
+			}
+			if s := lastSQL(t, fdb); s != "ROLLBACK" {
+				t.Errorf("last statement = %s", s)
+			}
+			if len(r.after) != 0 {
+				t.Errorf("After called: %v", r.after)
+			}
+		})
+	}
+}
+
+type Tally struct {
+	Id    int `xql:"type=serial,pk"`
+	Count int `xql:"type=integer"`
+}
+
+func (t Tally) TableName() string { return "tallies" }
+
+var tallies []*Tally
+
+func (t *Tally) PreDelete(table *xql.Table, s *xql.Session) error {
+	t.Count++
+	tallies = append(tallies, t)
+	return nil
+}
+
+func (t *Tally) PostDelete(table *xql.Table, s *xql.Session) error {
+	tallies = append(tallies, t)
+	return nil
+}
+
+var TallyTable = xql.DeclareTable(&Tally{})
+
+func TestHooksFreshEntity(t *testing.T) {
+	session, _ := openSession(t)
+	tallies = nil
+	for i := 0; i < 2; i++ {
+		if _, err := session.Table(TallyTable).Where("id", i).Delete(); nil != err {
+			t.Fatal(err)
+		}
+	}
+	// Pre and Post hooks of an operation share a new entity, never the declared one.
+	if len(tallies) != 4 || tallies[0] != tallies[1] || tallies[2] != tallies[3] || tallies[0] == tallies[2] {
+		t.Fatalf("hook entities = %v", tallies)
+	}
+	if tallies[0].Count != 1 || tallies[2].Count != 1 {
+		t.Errorf("counts = %d, %d, want 1", tallies[0].Count, tallies[2].Count)
+	}
+}
+
+type Widget struct {
+	Id   int    `xql:"type=serial,pk"`
+	Name string `xql:"size=32"`
+}
+
+func (w Widget) TableName() string { return "widgets" }
+
+func TestInterceptors(t *testing.T) {
+	session, _ := openSession(t)
+	r, tr := &recorder{}, &recorder{}
+	session.AddInterceptor(r)
+	q := session.Table(xql.DeclareTable(Widget{}).AddInterceptor(tr))
+	if _, err := q.Insert(Widget{Name: "a"}); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := q.Where("id", 1).Delete(); nil != err {
+		t.Fatal(err)
+	}
+	want := []xql.OperationType{xql.OperationInsert, xql.OperationDelete}
+	for _, x := range []*recorder{r, tr} {
+		if len(x.before) != 2 || x.before[0] != want[0] || x.before[1] != want[1] || len(x.after) != 2 {
+			t.Errorf("calls = %v %v", x.before, x.after)
+		}
+	}
+}
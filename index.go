@@ -20,27 +20,35 @@ const (
 type Index struct {
 	Type    uint8
 	Name    string
+	Class   string // Operator class of columns, like jsonb_path_ops, default of the type if empty
 	Columns []*Column
 }
 
+// parseIndexType
+// Which returns the index type of given name, IndexBTree for unknown names.
+func parseIndexType(s string) (uint8, bool) {
+	switch strings.ToLower(s) {
+	case "btree":
+		return IndexBTree, true
+	case "hash":
+		return IndexHash, true
+	case "gist":
+		return IndexGist, true
+	case "sp_gist", "sp-gist", "spgist":
+		return IndexSpGist, true
+	case "brin":
+		return IndexBrin, true
+	case "gin":
+		return IndexGin, true
+	}
+	return IndexBTree, false
+}
+
 func buildIndexes(t *Table, ss ...[2]string) []*Index {
 	var indexes []*Index
 	for _, xs := range ss {
 		idx := &Index{}
-		switch strings.ToLower(xs[0]) {
-		case "hash":
-			idx.Type = IndexHash
-		case "gist":
-			idx.Type = IndexGist
-		case "sp_gist", "sp-gist":
-			idx.Type = IndexSpGist
-		case "brin":
-			idx.Type = IndexBrin
-		case "gin":
-			idx.Type = IndexGin
-		default:
-			idx.Type = IndexBTree
-		}
+		idx.Type, _ = parseIndexType(xs[0])
 		for _, f := range strings.Split(xs[1], ",") {
 			if field, ok := t.GetColumn(f); ok {
 				idx.Columns = append(idx.Columns, field)
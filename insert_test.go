@@ -0,0 +1,220 @@
+package xql_test
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+type ArchivedBook struct {
+	Id    int    `xql:"type=serial,pk"`
+	Title string `xql:"size=64,unique"`
+	Label string `xql:"size=64,nullable"`
+}
+
+func (b ArchivedBook) TableName() string { return "archived_books" }
+
+var ArchivedBookTable = xql.DeclareTable(ArchivedBook{})
+
+func TestInsertFrom(t *testing.T) {
+	cases := []struct {
+		name     string
+		dst      []interface{}
+		src      []interface{}
+		where    bool
+		mappings [][2]string
+		want     string
+	}{
+		{"same names", nil, nil, true, nil,
+			`INSERT INTO archived_books ("id",title) SELECT "id","title" FROM books WHERE "id" > $1`},
+		{"by position", []interface{}{"label"}, []interface{}{"title"}, false, nil,
+			`INSERT INTO archived_books ("label") SELECT "title" FROM books`},
+		{"aliases", nil,
+			[]interface{}{xql.QueryColumn{FieldName: "title", Function: "upper", Alias: "label"}}, false, nil,
+			`INSERT INTO archived_books ("label") SELECT upper("title") AS "label" FROM books`},
+		{"mapped alias", nil,
+			[]interface{}{xql.QueryColumn{FieldName: "title", Function: "lower", Alias: "t"}}, false,
+			[][2]string{{"label", "t"}, {"title", "title"}},
+			`INSERT INTO archived_books ("label",title) SELECT lower("title") AS "t","title" FROM books`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			session, fdb := openSession(t)
+			dst, src := session.Table(ArchivedBookTable, c.dst...), session.Table(BookTable, c.src...)
+			if c.where {
+				src = src.Where("id", 1, ">")
+			}
+			if _, err := dst.InsertFrom(src, c.mappings...); nil != err {
+				t.Fatal(err)
+			}
+			if s := lastSQL(t, fdb); s != c.want {
+				t.Errorf("got  %s\nwant %s", s, c.want)
+			}
+		})
+	}
+}
+
+func TestInsertFromConflict(t *testing.T) {
+	session, fdb := openSession(t)
+	dst := session.Table(ArchivedBookTable).OnConflict(xql.ConflictDoUpdate, "title")
+	if _, err := dst.InsertFrom(session.Table(BookTable), [2]string{"title", "title"}, [2]string{"label", "'copy'"}); nil != err {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO archived_books (title,"label") SELECT "title",'copy' FROM books ON CONFLICT (title) DO UPDATE SET "label"=EXCLUDED."label"`
+	if s := lastSQL(t, fdb); s != want {
+		t.Errorf("got  %s\nwant %s", s, want)
+	}
+}
+
+func TestInsertFromLock(t *testing.T) {
+	session, fdb := openSession(t)
+	dst := session.Table(ArchivedBookTable).OnConflict(xql.ConflictDoNothing)
+	src := session.Table(BookTable, "title").Lock(xql.LockForShare, xql.LockSkipLocked)
+	if _, err := dst.InsertFrom(src); nil != err {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO archived_books (title) SELECT "title" FROM books FOR SHARE SKIP LOCKED ON CONFLICT DO NOTHING`
+	if s := lastSQL(t, fdb); s != want {
+		t.Errorf("got  %s\nwant %s", s, want)
+	}
+}
+
+func TestInsertFromErrors(t *testing.T) {
+	session, _ := openSession(t)
+	dst, src := session.Table(ArchivedBookTable), session.Table(BookTable)
+	if _, err := session.Table(ArchivedBookTable, "title", "label").InsertFrom(session.Table(BookTable, "title")); nil == err {
+		t.Error("expected error of unmatched columns")
+	}
+	if _, err := dst.InsertFrom(src, [2]string{"missing", "title"}); nil == err {
+		t.Error("expected error of invalid column")
+	}
+	if _, err := dst.InsertFrom(session.Table(BookTable, xql.QueryColumn{FieldName: "count(*)", Alias: "total"})); nil == err {
+		t.Error("expected error of alias not a column")
+	}
+}
+
+type Profile struct {
+	Id    int            `xql:"type=serial,pk"`
+	Name  string         `xql:"size=32,default=''"`
+	Nick  *string        `xql:"size=32"`
+	Score xql.Field[int] `xql:"type=integer"`
+	Note  *string        `xql:"type=text,default='none'"`
+	Level int            `xql:"always"`
+}
+
+func (p Profile) TableName() string { return "profiles" }
+
+var ProfileTable = xql.DeclareTable(Profile{})
+
+func TestInsertNullStates(t *testing.T) {
+	empty, nick := "", "neo"
+	cases := []struct {
+		name    string
+		profile Profile
+		want    string
+		args    int
+	}{
+		// Empty values are unset, NULL values written, zero values behind pointers or Field written.
+		{"unset and null", Profile{},
+			`INSERT INTO profiles (nick,score,"level") VALUES($1,$2,$3)`, 3},
+		{"zero", Profile{Nick: &empty, Score: xql.NewField(0)},
+			`INSERT INTO profiles (nick,score,"level") VALUES($1,$2,$3)`, 3},
+		{"values", Profile{Name: "n", Nick: &nick, Note: &nick, Level: 1},
+			`INSERT INTO profiles ("name",nick,score,note,"level") VALUES($1,$2,$3,$4,$5)`, 5},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			session, fdb := openSession(t)
+			if _, err := session.Table(ProfileTable).Insert(c.profile); nil != err {
+				t.Fatal(err)
+			}
+			s := fdb.Last()
+			if s.SQL != c.want || len(s.Args) != c.args {
+				t.Errorf("got  %s %v\nwant %s", s.SQL, s.Args, c.want)
+			}
+		})
+	}
+}
+
+func TestInsertNullValues(t *testing.T) {
+	session, fdb := openSession(t)
+	zero := ""
+	if _, err := session.Table(ProfileTable).Insert(Profile{}, Profile{Nick: &zero, Score: xql.NewField(0)}); nil != err {
+		t.Fatal(err)
+	}
+	ss := fdb.Statements()
+	if a := ss[0].Args; nil != a[0] || nil != a[1] {
+		t.Errorf("NULL args = %v", a)
+	}
+	if a := ss[1].Args; a[0] != "" || a[1] != int64(0) {
+		t.Errorf("zero args = %v", a)
+	}
+}
+
+type Sketch struct {
+	Id      int               `xql:"type=serial,pk"`
+	Name    string            `xql:"size=32"`
+	Note    *string           `xql:"type=text"`
+	Nick    xql.Field[string] `xql:"size=32"`
+	Created time.Time         `xql:"type=timestamp,autocreate"`
+}
+
+func (s Sketch) TableName() string { return "sketches" }
+
+var SketchTable = xql.DeclareTable(Sketch{})
+
+func TestUpdateNullStates(t *testing.T) {
+	cases := []struct {
+		name    string
+		columns []interface{}
+		want    string
+		args    []interface{}
+	}{
+		// NULL values and an empty 'autocreate' column are not set, unless listed.
+		{"partial", nil, `UPDATE sketches SET "name"=$1 WHERE "id" = $2`, []interface{}{"x", int64(1)}},
+		{"listed", []interface{}{"name", "note"}, `UPDATE sketches SET "name"=$1, note=$2 WHERE "id" = $3`,
+			[]interface{}{"x", nil, int64(1)}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			session, fdb := openSession(t)
+			qs := session.Table(SketchTable).Where("id", 1)
+			if len(c.columns) > 0 {
+				qs = qs.Columns(c.columns...)
+			}
+			if _, err := qs.Update(Sketch{Name: "x"}); nil != err {
+				t.Fatal(err)
+			}
+			assertStatement(t, fdb.Last(), c.want, c.args...)
+		})
+	}
+}
+
+func TestInsertSoftDeleteOmitted(t *testing.T) {
+	session, fdb := openSession(t)
+	session.SetClock(func() time.Time { return fixedNow })
+	if _, err := session.Table(MemoTable).Insert(Memo{Text: "a"}); nil != err {
+		t.Fatal(err)
+	}
+	if s := lastSQL(t, fdb); s != `INSERT INTO memos ("text",updated) VALUES($1,$2)` {
+		t.Errorf("sql = %s", s)
+	}
+}
+
+func TestInsertColumnsTouched(t *testing.T) {
+	session, fdb := openSession(t)
+	session.SetClock(func() time.Time { return fixedNow })
+	qs := session.Table(SketchTable).Columns("name")
+	if _, err := qs.Insert(Sketch{Name: "x"}); nil != err {
+		t.Fatal(err)
+	}
+	assertStatement(t, fdb.Last(), `INSERT INTO sketches ("name",created) VALUES($1,$2)`, "x", fixedNow)
+	var id int64
+	fdb.Push(rows("id", []driver.Value{int64(7)}))
+	if err := qs.InsertWithInsertedId(Sketch{Name: "x"}, "id", &id); nil != err || id != 7 {
+		t.Fatalf("InsertWithInsertedId() = %d, %v", id, err)
+	}
+	assertStatement(t, fdb.Last(), `INSERT INTO sketches ("name",created) VALUES($1,$2) RETURNING id`, "x", fixedNow)
+}
@@ -0,0 +1,233 @@
+// Package fakedb
+// A database/sql driver which records statements and returns queued results,
+// for tests of the SQL generated by xql without a database.
+package fakedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Statement
+// A statement received by the driver, BEGIN, COMMIT and ROLLBACK have no args.
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// Result
+// Rows returned by a query, Types are the database type names of Columns if given.
+// Err is returned by the iteration after all rows.
+type Result struct {
+	Columns []string
+	Types   []string
+	Rows    [][]driver.Value
+	Err     error
+}
+
+// DB
+// The recorder behind a *sql.DB opened by Open.
+type DB struct {
+	mu         sync.Mutex
+	statements []Statement
+	results    []Result
+	affected   int64
+	closed     int
+	// Fail returns an error for statements which should fail, if set.
+	Fail func(query string) error
+}
+
+// Open
+// Which opens a *sql.DB recorded by the returned DB. Every exec affects one row
+// unless changed by SetAffected.
+func Open() (*sql.DB, *DB) {
+	d := &DB{affected: 1}
+	return sql.OpenDB(connector{d}), d
+}
+
+// Push
+// Which queues results returned by following queries, in order. A query without
+// queued result returns no rows.
+func (d *DB) Push(results ...Result) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.results = append(d.results, results...)
+}
+
+// SetAffected
+// Which set the rows affected returned by execs.
+func (d *DB) SetAffected(n int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.affected = n
+}
+
+// Statements
+// Which returns statements received so far.
+func (d *DB) Statements() []Statement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Statement{}, d.statements...)
+}
+
+// SQL
+// Which returns the text of statements received so far.
+func (d *DB) SQL() []string {
+	var ss []string
+	for _, s := range d.Statements() {
+		ss = append(ss, s.SQL)
+	}
+	return ss
+}
+
+// Last
+// Which returns the last statement received, an empty Statement if none.
+func (d *DB) Last() Statement {
+	ss := d.Statements()
+	if len(ss) < 1 {
+		return Statement{}
+	}
+	return ss[len(ss)-1]
+}
+
+// Reset
+// Which forgets statements received and results queued.
+func (d *DB) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements, d.results, d.closed = nil, nil, 0
+}
+
+// Closed
+// Which returns how many result rows were closed.
+func (d *DB) Closed() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+func (d *DB) record(query string, args []driver.NamedValue) error {
+	d.mu.Lock()
+	s := Statement{SQL: query}
+	for _, a := range args {
+		s.Args = append(s.Args, a.Value)
+	}
+	d.statements = append(d.statements, s)
+	fail := d.Fail
+	d.mu.Unlock()
+	if nil != fail {
+		return fail(query)
+	}
+	return nil
+}
+
+type connector struct{ d *DB }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{c.d}, nil }
+func (c connector) Driver() driver.Driver                        { return drv{c.d} }
+
+type drv struct{ d *DB }
+
+func (x drv) Open(string) (driver.Conn, error) { return &conn{x.d}, nil }
+
+type conn struct{ d *DB }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{c.d, query}, nil }
+func (c *conn) Close() error                              { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	if err := c.d.record("BEGIN", nil); nil != err {
+		return nil, err
+	}
+	return tx{c.d}, nil
+}
+
+// CheckNamedValue
+// Which keeps values the default converter rejects, like slices, as they are.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value); nil == err {
+		nv.Value = v
+	}
+	return nil
+}
+
+type tx struct{ d *DB }
+
+func (t tx) Commit() error   { return t.d.record("COMMIT", nil) }
+func (t tx) Rollback() error { return t.d.record("ROLLBACK", nil) }
+
+type stmt struct {
+	d     *DB
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func (s *stmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.d.record(s.query, args); nil != err {
+		return nil, err
+	}
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	return driver.RowsAffected(s.d.affected), nil
+}
+
+func (s *stmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.d.record(s.query, args); nil != err {
+		return nil, err
+	}
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	var r Result
+	if len(s.d.results) > 0 {
+		r, s.d.results = s.d.results[0], s.d.results[1:]
+	}
+	return &rows{d: s.d, r: r}, nil
+}
+
+type rows struct {
+	d *DB
+	r Result
+	n int
+}
+
+func (r *rows) Columns() []string { return r.r.Columns }
+
+func (r *rows) Close() error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	r.d.closed++
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.n >= len(r.r.Rows) {
+		if nil != r.r.Err {
+			return r.r.Err
+		}
+		return io.EOF
+	}
+	copy(dest, r.r.Rows[r.n])
+	r.n++
+	return nil
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.r.Types) {
+		return strings.ToUpper(r.r.Types[i])
+	}
+	return ""
+}
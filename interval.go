@@ -0,0 +1,265 @@
+package xql
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// intervalFields
+// Fields of an interval, months and days are kept apart from time since their
+// lengths vary.
+type intervalFields struct {
+	months int32
+	days   int32
+	us     int64
+}
+
+// ParseInterval
+// Which parses interval text of all IntervalStyles of PostgreSQL to months, days and
+// microseconds: '1 year 2 mons 3 days 04:05:06.789' (postgres), '@ 1 year 2 mons 3 days
+// 4 hours 5 mins 6.789 secs ago' (postgres_verbose), '1-2 3 4:05:06.789' (sql_standard)
+// and 'P1Y2M3DT4H5M6.789S' (iso_8601).
+func ParseInterval(s string) (months int32, days int32, microseconds int64, err error) {
+	var x intervalFields
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "P") {
+		x, err = parseISOInterval(s)
+	} else {
+		x, err = parsePGInterval(s)
+	}
+	return x.months, x.days, x.us, err
+}
+
+// FormatInterval
+// Which formats an interval of months, days and microseconds in ISO 8601 format,
+// like P1Y2M3DT4H5M6.789S, accepted by all IntervalStyles.
+func FormatInterval(months int32, days int32, microseconds int64) string {
+	var sb strings.Builder
+	sb.WriteByte('P')
+	write := func(n int64, unit byte) {
+		if n != 0 {
+			sb.WriteString(strconv.FormatInt(n, 10))
+			sb.WriteByte(unit)
+		}
+	}
+	write(int64(months/12), 'Y')
+	write(int64(months%12), 'M')
+	write(int64(days), 'D')
+	if microseconds != 0 || sb.Len() == 1 {
+		sb.WriteByte('T')
+		us := microseconds
+		write(us/3600000000, 'H')
+		write(us%3600000000/60000000, 'M')
+		if us = us % 60000000; us != 0 || sb.Len() == 2 {
+			sb.WriteString(formatSeconds(us))
+			sb.WriteByte('S')
+		}
+	}
+	return sb.String()
+}
+
+// formatSeconds
+// Which formats microseconds us as seconds, without trailing zeros of fraction.
+func formatSeconds(us int64) string {
+	sign := ""
+	if us < 0 {
+		sign, us = "-", -us
+	}
+	s := strconv.FormatInt(us/1000000, 10)
+	if f := us % 1000000; f != 0 {
+		s += "." + strings.TrimRight(strconv.FormatInt(1000000+f, 10)[1:], "0")
+	}
+	return sign + s
+}
+
+// parseSeconds
+// Which parses seconds like -6.789 to microseconds.
+func parseSeconds(s string) (int64, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, errors.New("invalid seconds: " + s)
+	}
+	var us int64
+	if whole != "" {
+		n, e := strconv.ParseInt(whole, 10, 64)
+		if nil != e {
+			return 0, e
+		}
+		us = n * 1000000
+	}
+	if frac != "" {
+		frac = (frac + "000000")[:6]
+		n, e := strconv.ParseUint(frac, 10, 32)
+		if nil != e {
+			return 0, e
+		}
+		us += int64(n)
+	}
+	if neg {
+		us = -us
+	}
+	return us, nil
+}
+
+// parseClock
+// Which parses time of day like -04:05:06.789 to microseconds.
+func parseClock(s string) (int64, error) {
+	neg := strings.HasPrefix(s, "-")
+	parts := strings.Split(strings.TrimLeft(s, "+-"), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, errors.New("invalid interval time: " + s)
+	}
+	var us int64
+	for i, p := range parts[:2] {
+		n, e := strconv.ParseInt(p, 10, 64)
+		if nil != e {
+			return 0, e
+		}
+		if i == 0 {
+			us += n * 3600000000
+		} else {
+			us += n * 60000000
+		}
+	}
+	if len(parts) == 3 {
+		n, e := parseSeconds(parts[2])
+		if nil != e {
+			return 0, e
+		}
+		us += n
+	}
+	if neg {
+		us = -us
+	}
+	return us, nil
+}
+
+// add
+// Which adds n of unit to the interval, fractions of seconds only.
+func (i *intervalFields) add(n string, unit string) error {
+	unit = strings.ToLower(unit)
+	if unit == "s" || strings.HasPrefix(unit, "sec") {
+		us, e := parseSeconds(n)
+		i.us += us
+		return e
+	}
+	x, e := strconv.ParseInt(n, 10, 32)
+	if nil != e {
+		return errors.New("invalid interval number: " + n)
+	}
+	switch {
+	case unit == "y" || strings.HasPrefix(unit, "year"):
+		i.months += int32(x) * 12
+	case strings.HasPrefix(unit, "mon"):
+		i.months += int32(x)
+	case unit == "w" || strings.HasPrefix(unit, "week"):
+		i.days += int32(x) * 7
+	case unit == "d" || strings.HasPrefix(unit, "day"):
+		i.days += int32(x)
+	case unit == "h" || strings.HasPrefix(unit, "hour"):
+		i.us += x * 3600000000
+	case strings.HasPrefix(unit, "min"):
+		i.us += x * 60000000
+	default:
+		return errors.New("invalid interval unit: " + unit)
+	}
+	return nil
+}
+
+// yearMonthRex
+// Matches years-months of sql_standard style, like -1-2.
+var yearMonthRex = regexp.MustCompile(`^([+-]?)(\d+)-(\d+)$`)
+
+// parsePGInterval
+// Which parses interval of styles postgres, postgres_verbose and sql_standard. A number
+// without unit is days before a time of day, and seconds at the end.
+func parsePGInterval(s string) (intervalFields, error) {
+	var x intervalFields
+	fields := strings.Fields(s)
+	if len(fields) > 0 && fields[0] == "@" {
+		fields = fields[1:]
+	}
+	ago := len(fields) > 0 && strings.EqualFold(fields[len(fields)-1], "ago")
+	if ago {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) < 1 {
+		return x, errors.New("invalid interval: " + s)
+	}
+	for j := 0; j < len(fields); j++ {
+		f := fields[j]
+		if strings.Contains(f, ":") {
+			us, e := parseClock(f)
+			if nil != e {
+				return x, e
+			}
+			x.us += us
+		} else if m := yearMonthRex.FindStringSubmatch(f); nil != m {
+			if e := x.add(m[1]+m[2], "year"); nil != e {
+				return x, e
+			}
+			if e := x.add(m[1]+m[3], "mon"); nil != e {
+				return x, e
+			}
+		} else if j+1 < len(fields) && isIntervalUnit(fields[j+1]) {
+			if e := x.add(f, fields[j+1]); nil != e {
+				return x, e
+			}
+			j++
+		} else if j+1 < len(fields) && strings.Contains(fields[j+1], ":") {
+			if e := x.add(f, "day"); nil != e {
+				return x, e
+			}
+		} else if j+1 == len(fields) {
+			if e := x.add(f, "sec"); nil != e {
+				return x, e
+			}
+		} else {
+			return x, errors.New("invalid interval: " + s)
+		}
+	}
+	if ago {
+		x = intervalFields{-x.months, -x.days, -x.us}
+	}
+	return x, nil
+}
+
+// isIntervalUnit
+// Which tells if field s is a unit, like days.
+func isIntervalUnit(s string) bool {
+	return s != "" && (s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z')
+}
+
+// parseISOInterval
+// Which parses interval of ISO 8601 format with designators, like P1Y2M3DT4H5M6S.
+func parseISOInterval(s string) (intervalFields, error) {
+	var x intervalFields
+	date, clock, _ := strings.Cut(s[1:], "T")
+	for k, part := range []string{date, clock} {
+		for part != "" {
+			j := strings.IndexFunc(part, func(r rune) bool {
+				return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+			})
+			if j < 1 {
+				return x, errors.New("invalid interval: " + s)
+			}
+			unit := part[j : j+1]
+			if k == 1 && unit == "M" {
+				unit = "min"
+			} else if k == 0 && unit == "M" {
+				unit = "mon"
+			} else if (k == 0) != strings.Contains("YWD", unit) {
+				return x, errors.New("invalid interval: " + s)
+			}
+			if e := x.add(part[:j], unit); nil != e {
+				return x, e
+			}
+			part = part[j+1:]
+		}
+	}
+	return x, nil
+}
@@ -0,0 +1,106 @@
+package xql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	type fields struct {
+		months int32
+		days   int32
+		us     int64
+	}
+	full := fields{14, 3, 4*3600000000 + 5*60000000 + 6789000}
+	neg := fields{-14, 3, -(4*3600000000 + 5*60000000 + 6789000)}
+	cases := []struct {
+		style string
+		text  string
+		want  fields
+	}{
+		{"postgres", "1 year 2 mons 3 days 04:05:06.789", full},
+		{"postgres", "-1 years -2 mons +3 days -04:05:06.789", neg},
+		{"postgres", "3 days", fields{0, 3, 0}},
+		{"postgres", "00:00:00", fields{}},
+		{"postgres_verbose", "@ 1 year 2 mons 3 days 4 hours 5 mins 6.789 secs", full},
+		{"postgres_verbose", "@ 1 year 2 mons -3 days 4 hours 5 mins 6.789 secs ago", neg},
+		{"postgres_verbose", "@ 0", fields{}},
+		{"sql_standard", "1-2 3 4:05:06.789", full},
+		{"sql_standard", "-1-2 +3 -4:05:06.789", neg},
+		{"sql_standard", "1-2", fields{14, 0, 0}},
+		{"sql_standard", "3 0:00:00", fields{0, 3, 0}},
+		{"sql_standard", "0", fields{}},
+		{"iso_8601", "P1Y2M3DT4H5M6.789S", full},
+		{"iso_8601", "P-1Y-2M3DT-4H-5M-6.789S", neg},
+		{"iso_8601", "P1W", fields{0, 7, 0}},
+		{"iso_8601", "PT0S", fields{}},
+	}
+	for _, c := range cases {
+		t.Run(c.style+" "+c.text, func(t *testing.T) {
+			months, days, us, err := ParseInterval(c.text)
+			if nil != err {
+				t.Fatal(err)
+			}
+			if got := (fields{months, days, us}); got != c.want {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+	for _, bad := range []string{"", "@", "1 fortnight", "1 2 3", "P1H", "PT1Y", "1:2:3:4", "x-2"} {
+		if _, _, _, err := ParseInterval(bad); nil == err {
+			t.Errorf("ParseInterval(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	cases := []struct {
+		months, days int32
+		us           int64
+		want         string
+	}{
+		{14, 3, 4*3600000000 + 5*60000000 + 6789000, "P1Y2M3DT4H5M6.789S"},
+		{0, 0, 0, "PT0S"},
+		{0, -3, 0, "P-3D"},
+		{0, 0, -1500000, "PT-1.5S"},
+	}
+	for _, c := range cases {
+		s := FormatInterval(c.months, c.days, c.us)
+		if s != c.want {
+			t.Errorf("FormatInterval = %s, want %s", s, c.want)
+		}
+		months, days, us, err := ParseInterval(s)
+		if nil != err || months != c.months || days != c.days || us != c.us {
+			t.Errorf("ParseInterval(%s) = %d %d %d %v", s, months, days, us, err)
+		}
+	}
+}
+
+func TestIntervalScan(t *testing.T) {
+	cases := []struct {
+		src  interface{}
+		want time.Duration
+	}{
+		{[]byte("1 day 02:00:00"), 26 * time.Hour},
+		{"1 mon", 30 * 24 * time.Hour},
+		{"0 1:30:00", 90 * time.Minute},
+		{"PT1.5S", 1500 * time.Millisecond},
+		{"@ 2 mins ago", -2 * time.Minute},
+		{nil, 0},
+	}
+	for _, c := range cases {
+		i := Interval(time.Hour)
+		if err := i.Scan(c.src); nil != err {
+			t.Errorf("Scan(%v): %v", c.src, err)
+		} else if time.Duration(i) != c.want {
+			t.Errorf("Scan(%v) = %v, want %v", c.src, i, c.want)
+		}
+	}
+	var i Interval
+	if err := i.Scan(3.5); nil == err {
+		t.Error("Scan(float64) should fail")
+	}
+	if v, _ := Interval(90 * time.Minute).Value(); v != "PT1H30M" {
+		t.Errorf("Value() = %v", v)
+	}
+}
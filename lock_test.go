@@ -0,0 +1,62 @@
+package xql_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/archsh/go.xql"
+	"github.com/archsh/go.xql/internal/fakedb"
+)
+
+func TestLock(t *testing.T) {
+	cases := []struct {
+		name string
+		qs   func(xql.QuerySet) xql.QuerySet
+		want string
+	}{
+		{"update", func(q xql.QuerySet) xql.QuerySet { return q.Lock(xql.LockForUpdate, xql.LockWaitDefault) },
+			`SELECT "id","title" FROM books WHERE "id" = $1 LIMIT 1 FOR UPDATE`},
+		{"no key update nowait", func(q xql.QuerySet) xql.QuerySet { return q.Lock(xql.LockForNoKeyUpdate, xql.LockNoWait) },
+			`SELECT "id","title" FROM books WHERE "id" = $1 LIMIT 1 FOR NO KEY UPDATE NOWAIT`},
+		{"key share of", func(q xql.QuerySet) xql.QuerySet { return q.Lock(xql.LockForKeyShare, xql.LockSkipLocked, "books") },
+			`SELECT "id","title" FROM books WHERE "id" = $1 LIMIT 1 FOR KEY SHARE OF books SKIP LOCKED`},
+		{"raw", func(q xql.QuerySet) xql.QuerySet { return q.LockFor("SHARE") },
+			`SELECT "id","title" FROM books WHERE "id" = $1 LIMIT 1 FOR SHARE`},
+		{"none", func(q xql.QuerySet) xql.QuerySet { return q.Lock(xql.LockNone, xql.LockWaitDefault) },
+			`SELECT "id","title" FROM books WHERE "id" = $1 LIMIT 1`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			session, fdb := openSession(t)
+			_ = c.qs(session.Table(BookTable).Columns("id", "title")).Get(1)
+			if s := lastSQL(t, fdb); s != c.want {
+				t.Errorf("got  %s\nwant %s", s, c.want)
+			}
+		})
+	}
+}
+
+func TestLockErrors(t *testing.T) {
+	session, fdb := openSession(t)
+	for _, lock := range []xql.QuerySet{
+		session.Table(BookTable).Lock(xql.LockNone, xql.LockNoWait),
+		session.Table(BookTable).Lock(xql.LockForUpdate, xql.LockWaitDefault, "books; DROP TABLE books"),
+		session.Table(BookTable).Lock(xql.LockStrength(99), xql.LockWaitDefault),
+	} {
+		if _, err := lock.All(); nil == err {
+			t.Errorf("expected error, got %v", fdb.SQL())
+		}
+	}
+}
+
+func TestCountWithoutLock(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(fakedb.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(3)}}})
+	n, err := session.Table(BookTable).Where("title", "x").Lock(xql.LockForUpdate, xql.LockNoWait).Count()
+	if nil != err || n != 3 {
+		t.Fatalf("Count() = %d, %v", n, err)
+	}
+	if s := lastSQL(t, fdb); s != `SELECT COUNT("id") FROM books WHERE title = $1` {
+		t.Errorf("sql = %s", s)
+	}
+}
@@ -0,0 +1,92 @@
+package xql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrPermissionDenied
+// Which a PermissionError unwraps to, for checking with errors.Is.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// PermissionError
+// Which returned when an operation is not permitted on table, see TableCreatable,
+// TableUpdatable, TableReadable and TableDeletable.
+type PermissionError struct {
+	Table     string
+	Operation OperationType
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("permission denied: %s on table '%s'", e.Operation, e.Table)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return ErrPermissionDenied
+}
+
+// TableCreatableContext
+// Which like TableCreatable, decides with the context of session, e.g. the current user.
+type TableCreatableContext interface {
+	CreatableContext(ctx context.Context, session *Session) bool
+}
+
+type TableUpdatableContext interface {
+	UpdatableContext(ctx context.Context, session *Session) bool
+}
+
+type TableReadableContext interface {
+	ReadableContext(ctx context.Context, session *Session) bool
+}
+
+type TableDeletableContext interface {
+	DeletableContext(ctx context.Context, session *Session) bool
+}
+
+// permitted
+// Which tells if operation of type op is permitted on table by its entity.
+func (qs QuerySet) permitted(op OperationType) bool {
+	entity := qs.table.entity
+	ctx := qs.session.Context()
+	switch op {
+	case OperationInsert:
+		if x, ok := entity.(TableCreatable); ok && !x.Creatable() {
+			return false
+		}
+		if x, ok := entity.(TableCreatableContext); ok && !x.CreatableContext(ctx, qs.session) {
+			return false
+		}
+	case OperationUpdate:
+		if x, ok := entity.(TableUpdatable); ok && !x.Updatable() {
+			return false
+		}
+		if x, ok := entity.(TableUpdatableContext); ok && !x.UpdatableContext(ctx, qs.session) {
+			return false
+		}
+	case OperationDelete:
+		if x, ok := entity.(TableDeletable); ok && !x.Deletable() {
+			return false
+		}
+		if x, ok := entity.(TableDeletableContext); ok && !x.DeletableContext(ctx, qs.session) {
+			return false
+		}
+	case OperationRead:
+		if x, ok := entity.(TableReadable); ok && !x.Readable() {
+			return false
+		}
+		if x, ok := entity.(TableReadableContext); ok && !x.ReadableContext(ctx, qs.session) {
+			return false
+		}
+	}
+	return true
+}
+
+// permit
+// Which returns a PermissionError if operation of type op is not permitted on table.
+func (qs QuerySet) permit(op OperationType) error {
+	if !qs.permitted(op) {
+		return &PermissionError{Table: qs.table.TableName(), Operation: op}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package xql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+// Country
+// A read-only reference table.
+type Country struct {
+	Code string `xql:"size=2,pk"`
+	Name string `xql:"size=64"`
+}
+
+func (c Country) TableName() string { return "countries" }
+func (c Country) Creatable() bool   { return false }
+func (c Country) Updatable() bool   { return false }
+func (c Country) Deletable() bool   { return false }
+
+// AuditLog
+// An append-only table, readable by admins only.
+type AuditLog struct {
+	Id      int    `xql:"type=serial,pk"`
+	Message string `xql:"type=text"`
+}
+
+type roleKey struct{}
+
+func (a AuditLog) TableName() string { return "audit_logs" }
+func (a AuditLog) Updatable() bool   { return false }
+func (a AuditLog) Deletable() bool   { return false }
+
+func (a AuditLog) ReadableContext(ctx context.Context, session *xql.Session) bool {
+	return ctx.Value(roleKey{}) == "admin"
+}
+
+var (
+	CountryTable  = xql.DeclareTable(Country{})
+	AuditLogTable = xql.DeclareTable(AuditLog{})
+)
+
+func assertDenied(t *testing.T, err error, table string, op xql.OperationType) {
+	t.Helper()
+	var pe *xql.PermissionError
+	if !errors.Is(err, xql.ErrPermissionDenied) || !errors.As(err, &pe) {
+		t.Fatalf("error = %v, want permission denied", err)
+	}
+	if pe.Table != table || pe.Operation != op {
+		t.Errorf("denied %s on %s, want %s on %s", pe.Operation, pe.Table, op, table)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	session, fdb := openSession(t)
+	_, err := session.Table(CountryTable).Insert(Country{Code: "NZ", Name: "New Zealand"})
+	assertDenied(t, err, "countries", xql.OperationInsert)
+	_, err = session.Table(CountryTable).Where("code", "NZ").Update(map[string]interface{}{"name": "Aotearoa"})
+	assertDenied(t, err, "countries", xql.OperationUpdate)
+	_, err = session.Table(CountryTable).Where("code", "NZ").Delete()
+	assertDenied(t, err, "countries", xql.OperationDelete)
+	_, err = session.Table(AuditLogTable).Where("id", 1).Delete()
+	assertDenied(t, err, "audit_logs", xql.OperationDelete)
+	if n := len(fdb.Statements()); n != 0 {
+		t.Errorf("%d statements sent for denied operations: %v", n, fdb.SQL())
+	}
+
+	if _, err := session.Table(CountryTable, "code").All(); nil != err {
+		t.Errorf("read of countries: %v", err)
+	}
+	if _, err := session.Table(AuditLogTable).Insert(AuditLog{Message: "login"}); nil != err {
+		t.Errorf("insert of audit log: %v", err)
+	}
+}
+
+func TestPermissionsContext(t *testing.T) {
+	session, fdb := openSession(t)
+	_, err := session.Table(AuditLogTable, "id").All()
+	assertDenied(t, err, "audit_logs", xql.OperationRead)
+	err = session.Table(AuditLogTable).Get(1).Scan(&AuditLog{})
+	assertDenied(t, err, "audit_logs", xql.OperationRead)
+	_, err = session.Table(AuditLogTable).Count()
+	assertDenied(t, err, "audit_logs", xql.OperationRead)
+	_, err = session.Table(CountryTable).InsertFrom(session.Table(AuditLogTable, "message"), [2]string{"name", "message"})
+	if !errors.Is(err, xql.ErrPermissionDenied) {
+		t.Errorf("InsertFrom = %v, want permission denied", err)
+	}
+	if n := len(fdb.Statements()); n != 0 {
+		t.Errorf("%d statements sent for denied reads: %v", n, fdb.SQL())
+	}
+
+	session.SetContext(context.WithValue(context.Background(), roleKey{}, "admin"))
+	if _, err := session.Table(AuditLogTable, "id").All(); nil != err {
+		t.Errorf("read as admin: %v", err)
+	}
+}
@@ -21,11 +21,22 @@ type QueryFilter struct {
 	Operator  string // Value will not used if empty.
 	Function  string
 	Value     interface{}
+	Group     []QueryFilter // Filters grouped in parentheses, other fields are not used if not empty.
+	Args      []interface{} // Arguments of a raw Field (empty Operator), referred as $1, $2 ... in Field.
 }
 
+type DeletedMode uint8
+
+const (
+	DeletedExcluded DeletedMode = iota
+	DeletedIncluded
+	DeletedOnly
+)
+
 type QueryOrder struct {
 	Type  OrderType
 	Field string
+	Args  []interface{} // Arguments of Field as an expression, referred as $1, $2 ... in Field.
 }
 
 type QueryColumn struct {
@@ -36,8 +47,50 @@ type QueryColumn struct {
 
 type UpdateColumn struct {
 	Field    string
-	Operator string
+	Operator string // "=" sets Value, "+=" adds Value. Value will not used if empty, Field is a raw assignment then.
 	Value    interface{}
+	Args     []interface{} // Arguments of a raw Field (empty Operator), referred as $1, $2 ... in Field.
 }
 
 type QueryExtra map[string]interface{}
+
+type ConflictAction uint
+
+const (
+	ConflictNone ConflictAction = iota
+	ConflictDoNothing
+	ConflictDoUpdate
+)
+
+// QueryConflict describes the ON CONFLICT clause of an insert.
+// Columns is the conflict target, on ConflictDoUpdate every inserted column
+// outside the target is overwritten with the proposed value.
+type QueryConflict struct {
+	Action  ConflictAction
+	Columns []string
+}
+
+type LockStrength uint
+
+const (
+	LockNone LockStrength = iota
+	LockForUpdate
+	LockForNoKeyUpdate
+	LockForShare
+	LockForKeyShare
+)
+
+type LockWait uint
+
+const (
+	LockWaitDefault LockWait = iota
+	LockNoWait
+	LockSkipLocked
+)
+
+// QueryLock describes the row-locking clause of a select.
+type QueryLock struct {
+	Strength LockStrength
+	Wait     LockWait
+	Of       []string // Tables to lock, all tables if empty
+}
@@ -6,8 +6,14 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"time"
 )
 
+// ErrStaleObject
+// Which returned by updates and deletes of entities with a 'version' column, when
+// the row was modified or deleted by others since the entity was read.
+var ErrStaleObject = errors.New("stale object: modified or deleted since read")
+
 var pureFieldRex = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_]*$")
 
 func isPureField(s string) bool {
@@ -24,61 +30,113 @@ func (qc QueryColumn) String(as ...bool) string {
 		s = qc.FieldName
 	}
 	if qc.Alias != "" && len(as) > 0 && as[0] {
-		s = s + " AS " + qc.Alias
+		if isPureField(qc.Alias) {
+			s = fmt.Sprintf(`%s AS "%s"`, s, qc.Alias)
+		} else {
+			s = s + " AS " + qc.Alias
+		}
 	}
 	return s
 }
 
 type QuerySet struct {
-	session *Session
-	table   *Table
-	queries []QueryColumn
-	filters []QueryFilter
-	orders  []QueryOrder
-	lockFor string
-	offset  int64
-	limit   int64
+	session   *Session
+	table     *Table
+	queries   []QueryColumn
+	filters   []QueryFilter
+	orders    []QueryOrder
+	lockFor   string
+	lock      *QueryLock
+	offset    int64
+	limit     int64
+	conflict  *QueryConflict
+	tags      QueryTags
+	unmatched UnmatchedPolicy
+	preloads  []string
+	deleted   DeletedMode
+	unscoped  bool
 }
 
 type XRow struct {
-	row *sql.Row
-	qs  *QuerySet
+	rows *XRows
+	err  error
 }
 
+// Scan
+// Which scans the first row of result, sql.ErrNoRows if there is no row. See XRows.Scan.
 func (xr *XRow) Scan(dest ...interface{}) error {
-	if nil == xr.row {
-		return errors.New("nil row")
+	if nil != xr.err {
+		return xr.err
 	}
-	if len(dest) < 1 {
-		panic("Empty output!")
+	if nil == xr.rows {
+		return errors.New("nil row")
 	}
-	if len(dest) == 1 {
-		d := dest[0]
-		if reflect.TypeOf(d) == reflect.TypeOf(xr.qs.table.entity) || reflect.TypeOf(d).Elem() == reflect.TypeOf(xr.qs.table.entity) {
-			var outputs []interface{}
-			var r reflect.Value
-			if vv := reflect.ValueOf(d); vv.Kind() == reflect.Interface || vv.Kind() == reflect.Ptr || vv.Kind() == reflect.UnsafePointer {
-				r = vv.Elem()
-			} else {
-				r = vv
-			}
-			for _, qc := range xr.qs.queries {
-				c, _ := xr.qs.table.GetColumn(qc.FieldName)
-				//fmt.Printf("> Scan to field '%s' to '%s' .\n", qc.FieldName, c.ElemName)
-				vp := r.FieldByName(c.ElemName).Addr().Interface()
-				outputs = append(outputs, vp)
-			}
-			return xr.row.Scan(outputs...)
+	defer xr.rows.Close()
+	if !xr.rows.Next() {
+		if e := xr.rows.Err(); nil != e {
+			return e
 		}
+		return sql.ErrNoRows
+	}
+	if e := xr.rows.Scan(dest...); nil != e {
+		return e
 	}
-	return xr.row.Scan(dest...)
+	xr.rows.Close()
+	return xr.rows.Err()
+}
+
+// ScanRecord
+// Which scans the first row into a Record, sql.ErrNoRows if there is no row.
+func (xr *XRow) ScanRecord() (Record, error) {
+	var r Record
+	err := xr.Scan(&r)
+	return r, err
+}
+
+// ScanMap
+// Which scans the first row into a map keyed by column name, sql.ErrNoRows if there is no row.
+func (xr *XRow) ScanMap() (map[string]interface{}, error) {
+	var m map[string]interface{}
+	err := xr.Scan(&m)
+	return m, err
 }
 
 type XRows struct {
-	rows *sql.Rows
-	qs   *QuerySet
+	rows    *sql.Rows
+	qs      *QuerySet
+	err     error
+	columns []string
+	policy  UnmatchedPolicy
 }
 
+// Unmatched
+// Which set the policy of result columns not matching any struct field, ignored by default.
+func (xr *XRows) Unmatched(policy UnmatchedPolicy) *XRows {
+	xr.policy = policy
+	return xr
+}
+
+// Columns
+// Which returns the column names of result.
+func (xr *XRows) Columns() ([]string, error) {
+	if nil == xr.rows {
+		return nil, errors.New("no rows")
+	}
+	if nil == xr.columns {
+		cols, err := xr.rows.Columns()
+		if nil != err {
+			return nil, err
+		}
+		xr.columns = cols
+	}
+	return xr.columns, nil
+}
+
+// Scan
+// Which scans current row into dest. A single pointer to struct, which is not a
+// sql.Scanner, is filled by matching result columns to fields by xql name, json
+// tag or field name, including fields of embedded structs. A single pointer to
+// map[string]interface{} or Record is filled as ScanMap and ScanRecord do.
 func (xr *XRows) Scan(dest ...interface{}) error {
 	if nil == xr.rows {
 		return errors.New("no rows")
@@ -87,26 +145,62 @@ func (xr *XRows) Scan(dest ...interface{}) error {
 		panic("Empty output!")
 	}
 	if len(dest) == 1 {
-		d := dest[0]
-		if reflect.TypeOf(d) == reflect.TypeOf(xr.qs.table.entity) || reflect.TypeOf(d).Elem() == reflect.TypeOf(xr.qs.table.entity) {
-			var outputs []interface{}
-			var r reflect.Value
-			if vv := reflect.ValueOf(d); vv.Kind() == reflect.Interface || vv.Kind() == reflect.Ptr || vv.Kind() == reflect.UnsafePointer {
-				r = vv.Elem()
-			} else {
-				r = vv
+		switch d := dest[0].(type) {
+		case *map[string]interface{}:
+			r, err := xr.ScanRecord()
+			if nil != err {
+				return err
+			}
+			*d = r.Map()
+			return nil
+		case *Record:
+			r, err := xr.ScanRecord()
+			if nil != err {
+				return err
+			}
+			*d = r
+			return nil
+		}
+		if v, ok := structDest(dest[0]); ok {
+			cols, err := xr.Columns()
+			if nil != err {
+				return err
 			}
-			for _, qc := range xr.qs.queries {
-				c, _ := xr.qs.table.GetColumn(qc.FieldName)
-				vp := r.FieldByName(c.ElemName).Addr().Interface()
-				outputs = append(outputs, vp)
+			targets, err := scanTargets(v, cols, xr.policy)
+			if nil != err {
+				return err
 			}
-			return xr.rows.Scan(outputs...)
+			return xr.rows.Scan(targets...)
 		}
 	}
 	return xr.rows.Scan(dest...)
 }
 
+// ScanRecord
+// Which scans current row into a Record, values are converted to natural Go types
+// according to the column types, columns declared in the queried table are decoded
+// with their declared types.
+func (xr *XRows) ScanRecord() (Record, error) {
+	if nil == xr.rows {
+		return Record{}, errors.New("no rows")
+	}
+	var table *Table
+	if nil != xr.qs {
+		table = xr.qs.table
+	}
+	return scanRecord(xr.rows, table)
+}
+
+// ScanMap
+// Which scans current row into a map keyed by column name, see ScanRecord.
+func (xr *XRows) ScanMap() (map[string]interface{}, error) {
+	r, err := xr.ScanRecord()
+	if nil != err {
+		return nil, err
+	}
+	return r.Map(), nil
+}
+
 func (xr *XRows) Next() bool {
 	if nil == xr.rows {
 		return false
@@ -116,11 +210,229 @@ func (xr *XRows) Next() bool {
 
 func (xr *XRows) Close() {
 	if xr.rows != nil {
-		xr.rows.Close()
+		xr.err = xr.rows.Err()
+		if e := xr.rows.Close(); nil == xr.err {
+			xr.err = e
+		}
 		xr.rows = nil
 	}
 }
 
+// Err
+// Which returns the error encountered during iteration, also available after Close.
+func (xr *XRows) Err() error {
+	if xr.rows != nil {
+		return xr.rows.Err()
+	}
+	return xr.err
+}
+
+// Columns
+// Which set the columns to query, and also the columns to write on Insert and Update.
+// Listed columns are always written: a zero value is written as it is and a nil
+// pointer or invalid Field as NULL. Without columns, empty values are left out.
+// Automatic timestamps are also written on Insert and Update, even if not listed.
+func (qs QuerySet) Columns(columns ...interface{}) QuerySet {
+	qs.queries = nil
+	for _, c := range columns {
+		if qc, ok := c.(QueryColumn); ok {
+			qs.queries = append(qs.queries, qc)
+		} else if qcn, ok := c.(string); ok {
+			if col, ok := qs.table.GetColumn(qcn); !ok {
+				// Expressions are queried as they are, alias them with a QueryColumn.
+				qs.queries = append(qs.queries, QueryColumn{FieldName: qcn})
+			} else {
+				qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName})
+			}
+		} else {
+			panic("Unsupported parameter type!")
+		}
+	}
+	return qs
+}
+
+// writeColumns
+// Which returns the columns of obj to be written on insert or update, skips primary
+// keys if skipPK is true. Automatic timestamps are written on insert even if not
+// listed by Columns.
+func (qs QuerySet) writeColumns(obj interface{}, skipPK, insert bool) []string {
+	var cols []string
+	if len(qs.queries) > 0 {
+		listed := map[string]bool{}
+		for _, x := range qs.queries {
+			if c, ok := qs.table.GetColumn(x.FieldName); ok && c.Generated {
+				continue
+			}
+			cols = append(cols, x.FieldName)
+			listed[x.FieldName] = true
+		}
+		for _, col := range qs.table.columns {
+			if insert && (col.AutoCreate || col.AutoUpdate) && !listed[col.FieldName] {
+				cols = append(cols, col.FieldName)
+			}
+		}
+		return cols
+	}
+	r := reflect.Indirect(reflect.ValueOf(obj))
+	for _, col := range qs.table.columns {
+		if skipPK && col.PrimaryKey {
+			continue
+		}
+		if col.Omitted(r.FieldByName(col.ElemName), insert) {
+			continue
+		}
+		cols = append(cols, col.FieldName)
+	}
+	return cols
+}
+
+// touchEntity
+// Which returns obj with automatic timestamps set to now: 'autoupdate' columns always,
+// and 'autocreate' columns on insert if empty. obj is copied unless it is a pointer.
+func (qs QuerySet) touchEntity(obj interface{}, now time.Time, insert bool) (interface{}, error) {
+	r := reflect.ValueOf(obj)
+	if r.Kind() == reflect.Ptr {
+		r = r.Elem()
+	} else {
+		c := reflect.New(r.Type()).Elem()
+		c.Set(r)
+		r, obj = c, nil
+	}
+	for _, col := range qs.table.columns {
+		f := r.FieldByName(col.ElemName)
+		fill := col.AutoUpdate
+		if insert {
+			fill = (col.AutoCreate || col.AutoUpdate) && f.IsZero()
+		}
+		if fill {
+			if e := setTime(f, now); nil != e {
+				return nil, e
+			}
+		}
+	}
+	if nil == obj {
+		return r.Interface(), nil
+	}
+	return obj, nil
+}
+
+// touchColumns
+// Which appends 'autoupdate' columns not given in cols with value now.
+func (qs QuerySet) touchColumns(cols []UpdateColumn, now time.Time) []UpdateColumn {
+	for _, col := range qs.table.columns {
+		if !col.AutoUpdate {
+			continue
+		}
+		var given bool
+		for _, x := range cols {
+			if x.Field == col.FieldName {
+				given = true
+				break
+			}
+		}
+		if !given {
+			cols = append(cols, UpdateColumn{Field: col.FieldName, Operator: "=", Value: now})
+		}
+	}
+	return cols
+}
+
+// bumpVersion
+// Which appends the increment of the 'version' column to cols, if the table has one
+// and it is not set by cols.
+func (qs QuerySet) bumpVersion(cols []UpdateColumn) []UpdateColumn {
+	c, ok := qs.table.VersionColumn()
+	if !ok {
+		return cols
+	}
+	for _, x := range cols {
+		if x.Field == c.FieldName {
+			return cols
+		}
+	}
+	return append(cols, UpdateColumn{Field: c.FieldName, Operator: "+=", Value: 1})
+}
+
+// WithDeleted
+// Which includes soft deleted rows in the query.
+func (qs QuerySet) WithDeleted() QuerySet {
+	qs.deleted = DeletedIncluded
+	return qs
+}
+
+// OnlyDeleted
+// Which queries soft deleted rows only.
+func (qs QuerySet) OnlyDeleted() QuerySet {
+	qs.deleted = DeletedOnly
+	return qs
+}
+
+// whereFilters
+// Which returns the filters applied to statements: implicit filters of table,
+// like excluding soft deleted rows and default scopes, the extra filters given
+// and the filters of QuerySet grouped.
+func (qs QuerySet) whereFilters(extra ...QueryFilter) []QueryFilter {
+	var filters []QueryFilter
+	if c, ok := qs.table.SoftDeleteColumn(); ok {
+		switch qs.deleted {
+		case DeletedExcluded:
+			filters = append(filters, QueryFilter{Field: c.FieldName, Operator: "IS", Value: nil})
+		case DeletedOnly:
+			filters = append(filters, QueryFilter{Field: c.FieldName, Operator: "IS NOT", Value: nil})
+		}
+	}
+	filters = append(filters, qs.scopeFilters()...)
+	filters = append(filters, extra...)
+	if len(filters) < 1 {
+		return qs.filters
+	}
+	if len(qs.filters) > 0 {
+		filters = append(filters, QueryFilter{Group: qs.filters})
+	}
+	return filters
+}
+
+// Preload
+// Which eager loads relations of name, for entities returned by the typed Query[T].
+// For XRows use Session.LoadRelations on scanned entities instead.
+func (qs QuerySet) Preload(names ...string) QuerySet {
+	qs.preloads = append(append([]string{}, qs.preloads...), names...)
+	return qs
+}
+
+// Unmatched
+// Which set the policy of result columns not matching any struct field on Scan.
+func (qs QuerySet) Unmatched(policy UnmatchedPolicy) QuerySet {
+	qs.unmatched = policy
+	return qs
+}
+
+// Tag
+// Which attach a tag to statements issued by the QuerySet, rendered as a sqlcommenter
+// comment. See QueryTags for values allowed.
+func (qs QuerySet) Tag(key, value string) QuerySet {
+	qs.tags = qs.tags.merge(QueryTags{key: value})
+	return qs
+}
+
+// TagCaller
+// Which tag statements with the 'file:line' calling TagCaller as 'caller'.
+func (qs QuerySet) TagCaller() QuerySet {
+	return qs.Tag("caller", callerTag(1))
+}
+
+func (qs QuerySet) exec(s string, args ...interface{}) (sql.Result, error) {
+	return qs.session.exec(qs.session.annotate(s, qs.tags), args...)
+}
+
+func (qs QuerySet) query(s string, args ...interface{}) (*sql.Rows, error) {
+	return qs.session.query(qs.session.annotate(s, qs.tags), args...)
+}
+
+func (qs QuerySet) queryRow(s string, args ...interface{}) *sql.Row {
+	return qs.session.queryRow(qs.session.annotate(s, qs.tags), args...)
+}
+
 func makeQueryOrder(table *Table, s string) QueryOrder {
 	qo := QueryOrder{}
 	if s[:1] == "-" {
@@ -134,7 +446,7 @@ func makeQueryOrder(table *Table, s string) QueryOrder {
 }
 
 func Where(field string, val interface{}, ops ...string) QueryFilter {
-	f := QueryFilter{Field: field, Value: val, Operator: "="}
+	f := QueryFilter{Field: field, Value: filterValue(val), Operator: "="}
 	if len(ops) > 0 {
 		f.Operator = ops[0]
 		if len(ops) > 1 {
@@ -144,8 +456,29 @@ func Where(field string, val interface{}, ops ...string) QueryFilter {
 	return f
 }
 
+// filterValue
+// Which returns nil for NULL values like an invalid Field[T], to be rendered as IS NULL.
+func filterValue(v interface{}) interface{} {
+	if n, ok := v.(nullable); ok && n.isNull() {
+		return nil
+	}
+	return v
+}
+
+// IsNull
+// Which makes a filter of field IS NULL.
+func IsNull(field string) QueryFilter {
+	return QueryFilter{Field: field, Operator: "IS", Value: nil}
+}
+
+// IsNotNull
+// Which makes a filter of field IS NOT NULL.
+func IsNotNull(field string) QueryFilter {
+	return QueryFilter{Field: field, Operator: "IS NOT", Value: nil}
+}
+
 func (qs QuerySet) Where(field string, val interface{}, ops ...string) QuerySet {
-	f := QueryFilter{Field: field, Value: val, Operator: "="}
+	f := QueryFilter{Field: field, Value: filterValue(val), Operator: "="}
 	if len(ops) > 0 {
 		f.Operator = ops[0]
 		if len(ops) > 1 {
@@ -157,7 +490,7 @@ func (qs QuerySet) Where(field string, val interface{}, ops ...string) QuerySet
 }
 
 func (qs QuerySet) And(field string, val interface{}, ops ...string) QuerySet {
-	f := QueryFilter{Field: field, Value: val, Operator: "="}
+	f := QueryFilter{Field: field, Value: filterValue(val), Operator: "="}
 	if len(ops) > 0 {
 		f.Operator = ops[0]
 		if len(ops) > 1 {
@@ -169,7 +502,7 @@ func (qs QuerySet) And(field string, val interface{}, ops ...string) QuerySet {
 }
 
 func (qs QuerySet) Or(field string, val interface{}, ops ...string) QuerySet {
-	f := QueryFilter{Field: field, Value: val, Operator: "=", Condition: ConditionOr}
+	f := QueryFilter{Field: field, Value: filterValue(val), Operator: "=", Condition: ConditionOr}
 	if len(ops) > 0 {
 		f.Operator = ops[0]
 		if len(ops) > 1 {
@@ -182,6 +515,33 @@ func (qs QuerySet) Or(field string, val interface{}, ops ...string) QuerySet {
 
 func (qs QuerySet) LockFor(s string) QuerySet {
 	qs.lockFor = s
+	qs.lock = nil
+	return qs
+}
+
+// Lock
+// Which set typed row-locking options, like FOR UPDATE SKIP LOCKED, the options are
+// validated by the dialect when the query is executed.
+func (qs QuerySet) Lock(strength LockStrength, wait LockWait, of ...string) QuerySet {
+	qs.lock = &QueryLock{Strength: strength, Wait: wait, Of: of}
+	qs.lockFor = ""
+	return qs
+}
+
+func (qs QuerySet) lockClause() (string, error) {
+	if nil == qs.lock {
+		return qs.lockFor, nil
+	}
+	if d, ok := qs.session.getDialect().(IDialectLocker); ok {
+		return d.LockFor(*qs.lock)
+	}
+	return "", errors.New("row locking options are not supported by dialect: " + qs.session.driverName)
+}
+
+// OnConflict
+// Which set the conflict handling used by InsertFrom, columns is the conflict target.
+func (qs QuerySet) OnConflict(action ConflictAction, columns ...string) QuerySet {
+	qs.conflict = &QueryConflict{Action: action, Columns: columns}
 	return qs
 }
 
@@ -195,7 +555,7 @@ func (qs QuerySet) Filter(cons ...interface{}) QuerySet {
 			for k, v := range vm {
 				qs.filters = append(qs.filters, QueryFilter{
 					Field:    k,
-					Value:    v,
+					Value:    filterValue(v),
 					Operator: "=",
 				})
 			}
@@ -237,6 +597,9 @@ func (qs QuerySet) Limit(limit int64) QuerySet {
 	return qs
 }
 
+// Count
+// Which returns the count of rows matched. Rows are not locked, the lock of qs is
+// not used since locking clauses are not allowed with aggregate functions.
 func (qs QuerySet) Count(cols ...string) (int64, error) {
 	var fieldName string
 	if len(cols) > 0 {
@@ -246,13 +609,16 @@ func (qs QuerySet) Count(cols ...string) (int64, error) {
 	} else {
 		fieldName = qs.table.columns[0].FieldName
 	}
+	if err := qs.permit(OperationRead); nil != err {
+		return 0, err
+	}
 	s, args, err := qs.session.getDialect().Select(qs.table,
 		[]QueryColumn{{Function: "COUNT", FieldName: fieldName}},
-		qs.filters, nil, qs.lockFor, -1, -1)
+		qs.whereFilters(), nil, "", -1, -1)
 	if nil != err {
 		return 0, err
 	}
-	row := qs.session.QueryRow(s, args...)
+	row := qs.queryRow(s, args...)
 	var n int64
 	if e := row.Scan(&n); nil != e {
 		return 0, e
@@ -263,43 +629,59 @@ func (qs QuerySet) Count(cols ...string) (int64, error) {
 func (qs QuerySet) All() (*XRows, error) {
 	if len(qs.queries) < 1 {
 		for _, col := range qs.table.mColumns {
-			qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName, Alias: col.FieldName})
+			qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName})
 		}
 	}
+	if err := qs.permit(OperationRead); nil != err {
+		return nil, err
+	}
+	lockFor, err := qs.lockClause()
+	if nil != err {
+		return nil, err
+	}
 	s, args, err := qs.session.getDialect().Select(qs.table, qs.queries,
-		qs.filters, qs.orders, qs.lockFor, qs.offset, qs.limit)
+		qs.whereFilters(), qs.orders, lockFor, qs.offset, qs.limit)
 	if nil != err {
 		return nil, err
 	}
-	rows, err := qs.session.Query(s, args...)
+	rows, err := qs.query(s, args...)
 	if nil != err {
 		return nil, err
 	}
-	xrows := &XRows{rows: rows, qs: &qs}
+	xrows := &XRows{rows: rows, qs: &qs, policy: qs.unmatched}
 	return xrows, nil
 }
 
 func (qs QuerySet) One() *XRow {
 	if len(qs.queries) < 1 {
 		for _, col := range qs.table.mColumns {
-			qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName, Alias: col.FieldName})
+			qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName})
 		}
 	}
+	if err := qs.permit(OperationRead); nil != err {
+		return &XRow{err: err}
+	}
+	lockFor, err := qs.lockClause()
+	if nil != err {
+		return &XRow{err: err}
+	}
 	s, args, err := qs.session.getDialect().Select(qs.table, qs.queries,
-		qs.filters, qs.orders, qs.lockFor, qs.offset, 1)
+		qs.whereFilters(), qs.orders, lockFor, qs.offset, 1)
 	if nil != err {
-		return nil
+		return &XRow{err: err}
 	}
 	//fmt.Println("One:>", s, args)
-	row := qs.session.QueryRow(s, args...)
-	xrow := &XRow{row: row, qs: &qs}
-	return xrow
+	rows, err := qs.query(s, args...)
+	if nil != err {
+		return &XRow{err: err}
+	}
+	return &XRow{rows: &XRows{rows: rows, qs: &qs, policy: qs.unmatched}}
 }
 
 func (qs QuerySet) Get(pks ...interface{}) *XRow {
 	if len(qs.queries) < 1 {
 		for _, col := range qs.table.mColumns {
-			qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName, Alias: col.FieldName})
+			qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName})
 		}
 	}
 	if len(pks) != len(qs.table.primaryKeys) {
@@ -314,18 +696,36 @@ func (qs QuerySet) Get(pks ...interface{}) *XRow {
 		}
 		qs.filters = append(qs.filters, filter)
 	}
+	if err := qs.permit(OperationRead); nil != err {
+		return &XRow{err: err}
+	}
+	lockFor, err := qs.lockClause()
+	if nil != err {
+		return &XRow{err: err}
+	}
 	s, args, err := qs.session.getDialect().Select(qs.table, qs.queries,
-		qs.filters, qs.orders, qs.lockFor, qs.offset, 1)
+		qs.whereFilters(), qs.orders, lockFor, qs.offset, 1)
 	if nil != err {
-		return nil
+		return &XRow{err: err}
+	}
+	rows, err := qs.query(s, args...)
+	if nil != err {
+		return &XRow{err: err}
 	}
-	row := qs.session.QueryRow(s, args...)
-	xrow := &XRow{row: row, qs: &qs}
-	return xrow
+	return &XRow{rows: &XRows{rows: rows, qs: &qs, policy: qs.unmatched}}
 }
 
+// Update
+// Which updates rows matched with a map of columns, []UpdateColumn or an entity. On
+// tables with a 'version' column every update increases the version, unless it is set
+// by the columns given. Updating an entity also checks the version, and ErrStaleObject
+// is returned if it was modified or deleted since read.
 func (qs QuerySet) Update(vals interface{}) (int64, error) {
 	var cols []UpdateColumn
+	var filters []QueryFilter
+	var vcol *Column
+	var version reflect.Value
+	now := qs.session.Now()
 	//fmt.Println("Update:>", qs.table.mColumns)
 	if cm, ok := vals.(map[string]interface{}); ok {
 		for k, v := range cm {
@@ -345,113 +745,313 @@ func (qs QuerySet) Update(vals interface{}) (int64, error) {
 	} else if cx, ok := vals.([]UpdateColumn); ok {
 		cols = cx
 	} else if reflect.TypeOf(vals) == reflect.TypeOf(qs.table.entity) {
-		r := reflect.ValueOf(vals)
-		for _, col := range qs.table.columns {
-			if col.PrimaryKey {
-				continue
+		var err error
+		if vals, err = qs.touchEntity(vals, now, false); nil != err {
+			return 0, err
+		}
+		r := reflect.Indirect(reflect.ValueOf(vals))
+		if c, ok := qs.table.VersionColumn(); ok {
+			vcol, version = c, r.FieldByName(c.ElemName)
+			filters = append(filters, QueryFilter{Field: c.FieldName, Operator: "=", Value: version.Interface()})
+		}
+		for _, n := range qs.writeColumns(vals, true, false) {
+			col, ok := qs.table.GetColumn(n)
+			if !ok {
+				return 0, errors.New("Invalid column:" + n)
 			}
-			fv := reflect.Indirect(r).FieldByName(col.ElemName)
-			if (fv.Kind() == reflect.Ptr && fv.IsNil()) || reflect.Zero(fv.Type()) == fv {
+			if col == vcol {
 				continue
-			} else {
-				cols = append(cols, UpdateColumn{Field: col.FieldName, Operator: "=", Value: fv.Interface()})
 			}
+			cols = append(cols, UpdateColumn{Field: col.FieldName, Operator: "=", Value: r.FieldByName(col.ElemName).Interface()})
+		}
+	}
+	if len(cols) < 1 {
+		return 0, errors.New("no columns to update")
+	}
+	cols = qs.touchColumns(cols, now)
+	if err := qs.table.validateColumns(cols); nil != err {
+		return 0, err
+	}
+	cols = qs.bumpVersion(cols)
+	op := &Operation{Type: OperationUpdate, Table: qs.table, Filters: qs.whereFilters(filters...), Columns: cols}
+	if reflect.TypeOf(vals) == reflect.TypeOf(qs.table.entity) {
+		op.Entity = vals
+	}
+	n, err := qs.run(op, func(op *Operation) (int64, error) {
+		n, err := qs.execUpdate(op.Filters, op.Columns...)
+		if nil == err && nil != vcol && n < 1 {
+			return 0, ErrStaleObject
 		}
+		return n, err
+	})
+	if nil == err && version.CanSet() {
+		version.SetInt(version.Int() + 1)
 	}
-	s, args, err := qs.session.getDialect().Update(qs.table, qs.filters, cols...)
+	return n, err
+}
+
+func (qs QuerySet) execUpdate(filters []QueryFilter, cols ...UpdateColumn) (int64, error) {
+	s, args, err := qs.session.getDialect().Update(qs.table, filters, cols...)
 	if nil != err {
 		return 0, err
 	}
 	//fmt.Println(">>>Update:", s, args)
-	var ret sql.Result
-	ret, err = qs.session.Exec(s, args...)
+	ret, err := qs.exec(s, args...)
 	if nil != err {
 		return 0, err
-	} else {
-		rows, e := ret.RowsAffected()
-		return rows, e
 	}
-	return 0, nil
+	return ret.RowsAffected()
 }
 
-func (qs QuerySet) Delete() (int64, error) {
-	s, args, err := qs.session.getDialect().Delete(qs.table, qs.filters)
+// Delete
+// Which deletes rows matched, or the entities objs by primary keys if given. On tables
+// with a 'softdelete' column, rows are marked as deleted by setting the column to current
+// time instead (and the version increased), use HardDelete to remove them. On tables with
+// a 'version' column, deleting an entity returns ErrStaleObject if it was modified or
+// deleted since read.
+func (qs QuerySet) Delete(objs ...interface{}) (int64, error) {
+	if len(objs) < 1 {
+		return qs.delete(nil, qs.whereFilters())
+	}
+	if len(qs.table.primaryKeys) < 1 {
+		return 0, errors.New("no primary key of table: " + qs.table.TableName())
+	}
+	vcol, versioned := qs.table.VersionColumn()
+	var rows int64
+	for _, obj := range objs {
+		if reflect.TypeOf(obj) != reflect.TypeOf(qs.table.entity) {
+			return rows, errors.New(fmt.Sprintf("Invalid data type: %s <> %s", reflect.TypeOf(obj).String(),
+				reflect.TypeOf(qs.table.entity).String()))
+		}
+		r := reflect.Indirect(reflect.ValueOf(obj))
+		var filters []QueryFilter
+		for _, pk := range qs.table.primaryKeys {
+			filters = append(filters, QueryFilter{Field: pk.FieldName, Operator: "=", Value: r.FieldByName(pk.ElemName).Interface()})
+		}
+		if versioned {
+			filters = append(filters, QueryFilter{Field: vcol.FieldName, Operator: "=", Value: r.FieldByName(vcol.ElemName).Interface()})
+		}
+		n, err := qs.delete(obj, qs.whereFilters(filters...))
+		if nil != err {
+			return rows, err
+		} else if n < 1 && versioned {
+			return rows, ErrStaleObject
+		}
+		if _, soft := qs.table.SoftDeleteColumn(); soft && versioned {
+			if v := r.FieldByName(vcol.ElemName); v.CanSet() {
+				v.SetInt(v.Int() + 1)
+			}
+		}
+		rows += n
+	}
+	return rows, nil
+}
+
+func (qs QuerySet) delete(obj interface{}, filters []QueryFilter) (int64, error) {
+	op := &Operation{Type: OperationDelete, Table: qs.table, Entity: obj, Filters: filters}
+	return qs.run(op, func(op *Operation) (int64, error) {
+		if c, ok := qs.table.SoftDeleteColumn(); ok {
+			now := qs.session.Now()
+			cols := qs.touchColumns([]UpdateColumn{{Field: c.FieldName, Operator: "=", Value: now}}, now)
+			return qs.execUpdate(op.Filters, qs.bumpVersion(cols)...)
+		}
+		return qs.execDelete(op.Filters)
+	})
+}
+
+// HardDelete
+// Which deletes rows matched from table, even if the table has a 'softdelete' column.
+// Soft deleted rows are matched too, unless OnlyDeleted is set.
+func (qs QuerySet) HardDelete() (int64, error) {
+	if qs.deleted == DeletedExcluded {
+		qs = qs.WithDeleted()
+	}
+	op := &Operation{Type: OperationDelete, Table: qs.table, Filters: qs.whereFilters()}
+	return qs.run(op, func(op *Operation) (int64, error) {
+		return qs.execDelete(op.Filters)
+	})
+}
+
+func (qs QuerySet) execDelete(filters []QueryFilter) (int64, error) {
+	s, args, err := qs.session.getDialect().Delete(qs.table, filters)
 	if nil != err {
 		return 0, err
 	}
-	var ret sql.Result
-	ret, err = qs.session.Exec(s, args...)
+	ret, err := qs.exec(s, args...)
 	if nil != err {
 		return 0, err
-	} else {
-		rows, e := ret.RowsAffected()
-		return rows, e
 	}
-	return 0, nil
+	return ret.RowsAffected()
 }
 
-func (qs QuerySet) InsertWithInsertedId(obj interface{}, idname string, id interface{}) error {
-	var cols []string
-	if len(qs.queries) > 0 {
-		for _, x := range qs.queries {
-			cols = append(cols, x.FieldName)
-		}
+// Restore
+// Which restores soft deleted rows matched by clearing the 'softdelete' column,
+// 'autoupdate' columns and the 'version' column are updated as well.
+func (qs QuerySet) Restore() (int64, error) {
+	c, ok := qs.table.SoftDeleteColumn()
+	if !ok {
+		return 0, errors.New("no softdelete column of table: " + qs.table.TableName())
 	}
+	qs = qs.OnlyDeleted()
+	cols := qs.touchColumns([]UpdateColumn{{Field: c.FieldName, Operator: "=", Value: nil}}, qs.session.Now())
+	op := &Operation{Type: OperationUpdate, Table: qs.table, Filters: qs.whereFilters(),
+		Columns: qs.bumpVersion(cols)}
+	return qs.run(op, func(op *Operation) (int64, error) {
+		return qs.execUpdate(op.Filters, op.Columns...)
+	})
+}
+
+func (qs QuerySet) InsertWithInsertedId(obj interface{}, idname string, id interface{}) error {
 	if reflect.TypeOf(obj) != reflect.TypeOf(qs.table.entity) {
 		return errors.New(fmt.Sprintf("Invalid data type: %s(%s) <> %s", reflect.TypeOf(obj).String(), reflect.TypeOf(obj).Kind().String(),
 			reflect.TypeOf(qs.table.entity).String()))
 	}
-	if pobj, ok := obj.(TablePreInsert); ok {
-		pobj.PreInsert(qs.table, qs.session)
-	}
-	s, args, err := qs.session.getDialect().InsertWithInsertedId(qs.table, obj, idname, cols...)
-	if nil != err {
-		return err
-	}
-	//fmt.Println("Insert SQL:>", s, args)
-	err = qs.session.QueryRow(s, args...).Scan(id)
-	if nil != err {
-		//fmt.Println(">>>Insert SQL:>", s, args, err)
-		return err
-	} else {
-		if pobj, ok := obj.(TablePostInsert); ok {
-			pobj.PostInsert(qs.table, qs.session)
+	op := &Operation{Type: OperationInsert, Table: qs.table, Entity: obj}
+	_, err := qs.run(op, func(op *Operation) (int64, error) {
+		obj, err := qs.touchEntity(op.Entity, qs.session.Now(), true)
+		if nil != err {
+			return 0, err
 		}
-	}
-	return nil
+		cols := qs.writeColumns(obj, false, true)
+		if err := qs.table.Validate(obj, cols...); nil != err {
+			return 0, err
+		}
+		s, args, err := qs.session.getDialect().InsertWithInsertedId(qs.table, obj, idname, cols...)
+		if nil != err {
+			return 0, err
+		}
+		//fmt.Println("Insert SQL:>", s, args)
+		if err = qs.queryRow(s, args...).Scan(id); nil != err {
+			return 0, err
+		}
+		return 1, nil
+	})
+	return err
 }
 
+// Insert
+// Which inserts entities one by one. Hooks and interceptors run for each entity,
+// an error returned by them aborts the insert of remaining entities.
 func (qs QuerySet) Insert(objs ...interface{}) (int64, error) {
 	var rows int64 = 0
-	var cols []string
-	if len(qs.queries) > 0 {
-		for _, x := range qs.queries {
-			cols = append(cols, x.FieldName)
-		}
-	}
 	for _, obj := range objs {
 		if reflect.TypeOf(obj) != reflect.TypeOf(qs.table.entity) {
 			return 0, errors.New(fmt.Sprintf("Invalid data type: %s <> %s", reflect.TypeOf(obj).String(),
 				reflect.TypeOf(qs.table.entity).String()))
 		}
-		if pobj, ok := obj.(TablePreInsert); ok {
-			pobj.PreInsert(qs.table, qs.session)
+		op := &Operation{Type: OperationInsert, Table: qs.table, Entity: obj}
+		n, err := qs.run(op, func(op *Operation) (int64, error) {
+			obj, err := qs.touchEntity(op.Entity, qs.session.Now(), true)
+			if nil != err {
+				return 0, err
+			}
+			cols := qs.writeColumns(obj, false, true)
+			if err := qs.table.Validate(obj, cols...); nil != err {
+				return 0, err
+			}
+			s, args, err := qs.session.getDialect().Insert(qs.table, obj, cols...)
+			if nil != err {
+				return 0, err
+			}
+			//fmt.Println("Insert SQL:>", s, args)
+			if _, err = qs.exec(s, args...); nil != err {
+				return 0, err
+			}
+			return 1, nil
+		})
+		if nil != err {
+			return 0, err
+		}
+		rows += n
+	}
+	return rows, nil
+}
+
+// InsertFrom
+// Which copy rows selected by src into the table of qs with INSERT INTO ... SELECT ...
+// Each mapping is a pair of target column and source column, alias or expression.
+// Without mappings the columns of qs and src are paired by position when both are
+// given, columns of src are inserted into the columns named by their alias (or name)
+// when only src has columns, otherwise all columns with the same name in both tables
+// are copied. Functions of source columns and the lock of src are kept.
+func (qs QuerySet) InsertFrom(src QuerySet, mappings ...[2]string) (int64, error) {
+	d, ok := qs.session.getDialect().(IDialectInsertSelect)
+	if !ok {
+		return 0, errors.New("INSERT ... SELECT is not supported by dialect: " + qs.session.driverName)
+	}
+	if err := src.permit(OperationRead); nil != err {
+		return 0, err
+	}
+	lockFor, err := src.lockClause()
+	if nil != err {
+		return 0, err
+	}
+	var cols []string
+	var queries []QueryColumn
+	if len(mappings) > 0 {
+		for _, m := range mappings {
+			cols = append(cols, m[0])
+			queries = append(queries, src.sourceColumn(m[1]))
+		}
+	} else if len(src.queries) > 0 && len(qs.queries) == len(src.queries) {
+		for i, x := range qs.queries {
+			cols = append(cols, x.FieldName)
+			queries = append(queries, src.queries[i])
+		}
+	} else if len(src.queries) > 0 && len(qs.queries) < 1 {
+		for _, x := range src.queries {
+			if x.Alias != "" {
+				cols = append(cols, x.Alias)
+			} else {
+				cols = append(cols, x.FieldName)
+			}
+			queries = append(queries, x)
 		}
-		s, args, err := qs.session.getDialect().Insert(qs.table, obj, cols...)
+	} else if len(src.queries) > 0 || len(qs.queries) > 0 {
+		return 0, errors.New("columns of both QuerySets do not match")
+	} else {
+		for _, col := range qs.table.columns {
+			if c, ok := src.table.GetColumn(col.FieldName); ok {
+				cols = append(cols, col.FieldName)
+				queries = append(queries, QueryColumn{FieldName: c.FieldName})
+			}
+		}
+	}
+	if len(cols) < 1 {
+		return 0, errors.New("no columns to insert")
+	}
+	for i, name := range cols {
+		c, ok := qs.table.GetColumn(name)
+		if !ok {
+			return 0, errors.New("Invalid column:" + name)
+		}
+		cols[i] = c.FieldName
+	}
+	op := &Operation{Type: OperationInsert, Table: qs.table, Filters: src.whereFilters()}
+	return qs.run(op, func(op *Operation) (int64, error) {
+		s, args, err := d.InsertSelect(qs.table, cols, src.table, queries,
+			op.Filters, src.orders, lockFor, src.offset, src.limit, qs.conflict)
 		if nil != err {
 			return 0, err
 		}
-		//fmt.Println("Insert SQL:>", s, args)
-		_, err = qs.session.Exec(s, args...)
+		ret, err := qs.exec(s, args...)
 		if nil != err {
-			//fmt.Println(">>>Insert SQL:>", s, args, err)
 			return 0, err
-		} else {
-			if pobj, ok := obj.(TablePostInsert); ok {
-				pobj.PostInsert(qs.table, qs.session)
-			}
-			rows += 1
+		}
+		return ret.RowsAffected()
+	})
+}
+
+// sourceColumn
+// Which returns the column of qs queried under name, which is an alias, a column or an expression.
+func (qs QuerySet) sourceColumn(name string) QueryColumn {
+	for _, x := range qs.queries {
+		if x.Alias == name {
+			return x
 		}
 	}
-	return rows, nil
+	if c, ok := qs.table.GetColumn(name); ok {
+		return QueryColumn{FieldName: c.FieldName}
+	}
+	return QueryColumn{FieldName: name}
 }
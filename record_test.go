@@ -0,0 +1,111 @@
+package xql_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/archsh/go.xql"
+	"github.com/archsh/go.xql/dialects/postgres"
+	"github.com/archsh/go.xql/internal/fakedb"
+)
+
+type Shelf struct {
+	Id    int                  `xql:"type=serial,pk"`
+	Tags  postgres.StringArray `xql:"size=16"`
+	Attrs postgres.HSTORE
+	Meta  postgres.JSONB[map[string]int]
+}
+
+func (s Shelf) TableName() string { return "shelves" }
+
+var ShelfTable = xql.DeclareTable(Shelf{})
+
+func TestScanRecord(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(fakedb.Result{
+		Columns: []string{"id", "name", "doc", "data", "score", "missing"},
+		Types:   []string{"int8", "text", "jsonb", "bytea", "numeric", "text"},
+		Rows:    [][]driver.Value{{int64(1), []byte("hall"), []byte(`{"a":[1,"x"]}`), []byte{0, 1}, []byte("1.50"), nil}},
+	})
+	xrows, err := session.QueryRows("SELECT ...")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer xrows.Close()
+	if !xrows.Next() {
+		t.Fatal("no row")
+	}
+	r, err := xrows.ScanRecord()
+	if nil != err {
+		t.Fatal(err)
+	}
+	want := []interface{}{int64(1), "hall", map[string]interface{}{"a": []interface{}{float64(1), "x"}},
+		[]byte{0, 1}, "1.50", nil}
+	if !reflect.DeepEqual(r.Columns, []string{"id", "name", "doc", "data", "score", "missing"}) ||
+		!reflect.DeepEqual(r.Values, want) {
+		t.Errorf("record = %#v", r)
+	}
+	if v, ok := r.Get("name"); !ok || v != "hall" {
+		t.Errorf("Get(name) = %v, %v", v, ok)
+	}
+	if _, ok := r.Get("nothing"); ok {
+		t.Error("Get(nothing) found")
+	}
+	if m := r.Map(); len(m) != 6 || m["score"] != "1.50" {
+		t.Errorf("Map() = %v", m)
+	}
+}
+
+func TestScanMapDeclaredColumns(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(fakedb.Result{
+		Columns: []string{"id", "tags", "attrs", "meta"},
+		Types:   []string{"int4", "_varchar", "hstore", "jsonb"},
+		Rows:    [][]driver.Value{{int64(2), []byte(`{a,"b c"}`), []byte(`"k"=>"v", "n"=>NULL`), []byte(`{"x":1}`)}},
+	})
+	m, err := session.Table(ShelfTable).Columns("id", "tags", "attrs", "meta").One().ScanMap()
+	if nil != err {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"id":    int64(2),
+		"tags":  postgres.StringArray{"a", "b c"},
+		"attrs": postgres.HSTORE{"k": "v", "n": nil},
+		"meta":  postgres.JSONB[map[string]int]{V: map[string]int{"x": 1}},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("map = %#v", m)
+	}
+
+	// A raw query does not know the table, values are natural types.
+	fdb.Push(fakedb.Result{
+		Columns: []string{"tags"},
+		Types:   []string{"_varchar"},
+		Rows:    [][]driver.Value{{[]byte(`{a}`)}},
+	})
+	xrows, _ := session.QueryRows("SELECT tags FROM shelves")
+	defer xrows.Close()
+	xrows.Next()
+	var raw map[string]interface{}
+	if err := xrows.Scan(&raw); nil != err || raw["tags"] != "{a}" {
+		t.Errorf("raw = %#v, %v", raw, err)
+	}
+}
+
+func TestScanRecordErrors(t *testing.T) {
+	session, fdb := openSession(t)
+	if _, err := session.Table(ShelfTable).One().ScanRecord(); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("no rows = %v", err)
+	}
+	fdb.Push(fakedb.Result{
+		Columns: []string{"doc"},
+		Types:   []string{"json"},
+		Rows:    [][]driver.Value{{[]byte(`{"a":`)}},
+	})
+	if _, err := session.Table(ShelfTable).Columns("doc").One().ScanMap(); nil == err {
+		t.Error("invalid json should fail")
+	}
+}
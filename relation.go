@@ -0,0 +1,495 @@
+package xql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Relation Types:
+//   - BELONGS TO: Key on this table references the target, like Student.School
+//   - HAS ONE: Key on the target references this table
+//   - HAS MANY: Key on the target references this table, like School.Students
+//   - MANY TO MANY: Through a join table, like Teacher.Schools
+const (
+	RelationNone uint8 = iota
+	RelationBelongsTo
+	RelationHasOne
+	RelationHasMany
+	RelationManyToMany
+	RelationInvalid
+)
+
+// Relation
+// Declared on an entity field, with tags like:
+//
+//	School   *School   `xql:"rel=belongs_to,key=school_id"`
+//	Students []Student `xql:"rel=has_many,key=school_id"`
+//	Schools  []School  `xql:"rel=many_to_many,through=school_teachers(teacher_id,school_id)"`
+//
+// Without 'key', Key is inferred when loaded from the column declared with a 'fk' tag
+// referencing the other table (on this table for BELONGS TO, on the target for HAS ONE
+// and HAS MANY), and defaults to '<field>_id' for BELONGS TO and '<entity>_id' for
+// HAS ONE/HAS MANY. References defaults to the column referenced by the 'fk' tag, or
+// the primary key of the referenced table.
+type Relation struct {
+	Type       uint8
+	Name       string
+	Key        string   // Column referencing the other side, empty if inferred
+	References string   // Column referenced by Key
+	Through    string   // Join table of MANY TO MANY
+	ThroughKey []string // Join table columns referencing this table and the target
+	Target     reflect.Type
+	index      []int
+	table      *Table
+}
+
+// TableRelated
+// Which allow struct to define a method Relations() to declare relations without tags
+// field name, type, options, like {"School", "belongs_to", "key=school_id"}
+type TableRelated interface {
+	Relations() [][3]string
+}
+
+var tableRegistry sync.Map
+
+func entityType(entity interface{}) reflect.Type {
+	et := reflect.TypeOf(entity)
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	return et
+}
+
+func registerTable(t *Table) {
+	tableRegistry.Store(entityType(t.entity), t)
+}
+
+// TableOf
+// Which returns the declared Table of an entity type.
+func TableOf(entity interface{}) (*Table, bool) {
+	if t, ok := tableRegistry.Load(entityType(entity)); ok {
+		return t.(*Table), true
+	}
+	return nil, false
+}
+
+func parseRelationType(s string) uint8 {
+	switch strings.Replace(strings.ToLower(s), "-", "_", -1) {
+	case "belongs_to", "belongsto":
+		return RelationBelongsTo
+	case "has_one", "hasone":
+		return RelationHasOne
+	case "has_many", "hasmany":
+		return RelationHasMany
+	case "many_to_many", "manytomany", "m2m":
+		return RelationManyToMany
+	}
+	return RelationInvalid
+}
+
+// isRelationField
+// Which tells if a struct field declares a relation but a column.
+func isRelationField(f reflect.StructField) bool {
+	props, e := ParseProperties(f.Tag.Get("xql"))
+	return nil == e && props.HasKey("rel")
+}
+
+func makeRelation(t *Table, f reflect.StructField, kind string, props PropertySet) *Relation {
+	r := &Relation{Type: parseRelationType(kind), Name: f.Name, index: f.Index}
+	if r.Type == RelationInvalid {
+		panic(fmt.Sprintf("Invalid relation type '%s' of '%s'!", kind, f.Name))
+	}
+	r.Target = f.Type
+	for r.Target.Kind() == reflect.Ptr || r.Target.Kind() == reflect.Slice {
+		r.Target = r.Target.Elem()
+	}
+	if r.Target.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Invalid relation field '%s' of '%s'!", f.Name, t.TableName()))
+	}
+	r.Key, _ = props.GetString("key")
+	r.References, _ = props.GetString("references")
+	switch r.Type {
+	case RelationManyToMany:
+		through, _ := props.GetString("through")
+		if i := strings.Index(through, "("); i > 0 && strings.HasSuffix(through, ")") {
+			r.Through = through[:i]
+			for _, k := range strings.Split(through[i+1:len(through)-1], ",") {
+				r.ThroughKey = append(r.ThroughKey, strings.TrimSpace(k))
+			}
+		}
+		if r.Through == "" || len(r.ThroughKey) != 2 {
+			panic(fmt.Sprintf("Relation '%s' requires through=table(key,target_key)!", f.Name))
+		}
+	}
+	r.table = t
+	return r
+}
+
+// makeRelations
+// Make a list of &Relation{} objects according to tags and TableRelated of entity.
+func makeRelations(t *Table, entity interface{}) []*Relation {
+	var relations []*Relation
+	et := entityType(entity)
+	var walk func(reflect.Type, []int)
+	walk = func(st reflect.Type, prefix []int) {
+		for i := 0; i < st.NumField(); i++ {
+			f := st.Field(i)
+			f.Index = append(append([]int{}, prefix...), i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				walk(f.Type, f.Index)
+				continue
+			}
+			if !isRelationField(f) {
+				continue
+			}
+			props, _ := ParseProperties(f.Tag.Get("xql"))
+			kind, _ := props.GetString("rel")
+			relations = append(relations, makeRelation(t, f, kind, props))
+		}
+	}
+	walk(et, nil)
+	if tr, ok := entity.(TableRelated); ok {
+		for _, xs := range tr.Relations() {
+			f, ok := et.FieldByName(xs[0])
+			if !ok {
+				panic(fmt.Sprintf("Can not get field '%s' from '%s'!", xs[0], t.TableName()))
+			}
+			props, e := ParseProperties(xs[2])
+			if nil != e {
+				panic(e)
+			}
+			relations = append(relations, makeRelation(t, f, xs[1], props))
+		}
+	}
+	return relations
+}
+
+// joinTable
+// A table identified by name only, used for join tables of MANY TO MANY.
+type joinTable string
+
+func (j joinTable) TableName() string {
+	return string(j)
+}
+
+func (r *Relation) targetTable() (*Table, error) {
+	if t, ok := tableRegistry.Load(r.Target); ok {
+		return t.(*Table), nil
+	}
+	return nil, errors.New("table of relation not declared: " + r.Target.String())
+}
+
+// foreignKeyTo
+// Which returns the column of t declared with a 'fk' tag referencing table ref, and the
+// column referenced, empty if the primary key of ref.
+func foreignKeyTo(t *Table, ref *Table) (*Column, string, bool) {
+	for _, c := range t.columns {
+		for _, x := range c.Constraints {
+			if x.Type != ConstraintForeignKey {
+				continue
+			}
+			name, column := x.Statement, ""
+			if i := strings.Index(name, "("); i > 0 && strings.HasSuffix(name, ")") {
+				name, column = name[:i], strings.TrimSpace(name[i+1:len(name)-1])
+			} else if i := strings.LastIndex(name, "."); i > 0 {
+				name, column = name[:i], name[i+1:]
+			}
+			if name = strings.TrimSpace(name); name == ref.TableName() || name == ref.BaseTableName() {
+				return c, column, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// keys
+// Which returns the key column and the column it references, of BELONGS TO, HAS ONE
+// and HAS MANY relations with the target table.
+func (r *Relation) keys(target *Table) (string, string, error) {
+	owner, referenced := r.table, target
+	if r.Type != RelationBelongsTo {
+		owner, referenced = target, r.table
+	}
+	key, refs := r.Key, r.References
+	if key == "" {
+		if c, column, ok := foreignKeyTo(owner, referenced); ok {
+			key = c.FieldName
+			if refs == "" {
+				refs = column
+			}
+		} else if r.Type == RelationBelongsTo {
+			key = Camel2Underscore(r.Name) + "_id"
+		} else {
+			key = Camel2Underscore(entityType(r.table.entity).Name()) + "_id"
+		}
+	}
+	if refs == "" {
+		pk, err := primaryKeyOf(referenced)
+		if nil != err {
+			return "", "", err
+		}
+		refs = pk.FieldName
+	}
+	return key, refs, nil
+}
+
+func primaryKeyOf(t *Table) (*Column, error) {
+	if len(t.primaryKeys) != 1 {
+		return nil, errors.New("single primary key required by relation: " + t.TableName())
+	}
+	return t.primaryKeys[0], nil
+}
+
+// relationKey
+// Which normalizes a key value, so that values of struct fields and scanned values match.
+func relationKey(v interface{}) string {
+	if vv, ok := v.(driver.Valuer); ok {
+		if x, e := vv.Value(); nil == e {
+			v = x
+		}
+	}
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	if b, ok := rv.Interface().([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+func columnValue(t *Table, v reflect.Value, name string) (reflect.Value, error) {
+	c, ok := t.GetColumn(name)
+	if !ok {
+		return reflect.Value{}, errors.New("Invalid column:" + name)
+	}
+	return v.FieldByName(c.ElemName), nil
+}
+
+// setRelation
+// Which assigns target values to the relation field of v.
+func setRelation(r *Relation, v reflect.Value, targets []reflect.Value) {
+	f := fieldByPath(v, r.index)
+	switch f.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(f.Type(), 0, len(targets))
+		for _, x := range targets {
+			if f.Type().Elem().Kind() == reflect.Ptr {
+				s = reflect.Append(s, x.Addr())
+			} else {
+				s = reflect.Append(s, x)
+			}
+		}
+		f.Set(s)
+	case reflect.Ptr:
+		if len(targets) > 0 {
+			f.Set(targets[0].Addr())
+		} else {
+			f.Set(reflect.Zero(f.Type()))
+		}
+	default:
+		if len(targets) > 0 {
+			f.Set(targets[0])
+		} else {
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+}
+
+// fetchTargets
+// Which queries target rows with column in values, grouped by the key of column.
+func fetchTargets(session *Session, table *Table, column string, values []interface{}) (map[string][]reflect.Value, error) {
+	ret := make(map[string][]reflect.Value)
+	if len(values) < 1 {
+		return ret, nil
+	}
+	rows, err := session.Table(table).Where(column, values, "IN").All()
+	if nil != err {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		x := reflect.New(reflect.TypeOf(table.entity))
+		if e := rows.Scan(x.Interface()); nil != e {
+			return nil, e
+		}
+		kv, e := columnValue(table, x.Elem(), column)
+		if nil != e {
+			return nil, e
+		}
+		k := relationKey(kv.Interface())
+		ret[k] = append(ret[k], x.Elem())
+	}
+	return ret, rows.Err()
+}
+
+// keyValues
+// Which returns distinct values of column in items, with keys of each item.
+func keyValues(t *Table, items []reflect.Value, column string) ([]interface{}, []string, error) {
+	var values []interface{}
+	keys := make([]string, len(items))
+	seen := make(map[string]bool)
+	for i, v := range items {
+		kv, err := columnValue(t, v, column)
+		if nil != err {
+			return nil, nil, err
+		}
+		k := relationKey(kv.Interface())
+		keys[i] = k
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		values = append(values, kv.Interface())
+	}
+	return values, keys, nil
+}
+
+// load
+// Which loads the relation of items in batch, items are addressable entity values.
+func (r *Relation) load(session *Session, items []reflect.Value) error {
+	target, err := r.targetTable()
+	if nil != err {
+		return err
+	}
+	switch r.Type {
+	case RelationBelongsTo:
+		key, refs, err := r.keys(target)
+		if nil != err {
+			return err
+		}
+		values, keys, err := keyValues(r.table, items, key)
+		if nil != err {
+			return err
+		}
+		found, err := fetchTargets(session, target, refs, values)
+		if nil != err {
+			return err
+		}
+		for i, v := range items {
+			setRelation(r, v, found[keys[i]])
+		}
+	case RelationHasOne, RelationHasMany:
+		key, refs, err := r.keys(target)
+		if nil != err {
+			return err
+		}
+		values, keys, err := keyValues(r.table, items, refs)
+		if nil != err {
+			return err
+		}
+		found, err := fetchTargets(session, target, key, values)
+		if nil != err {
+			return err
+		}
+		for i, v := range items {
+			setRelation(r, v, found[keys[i]])
+		}
+	case RelationManyToMany:
+		pk, err := primaryKeyOf(r.table)
+		if nil != err {
+			return err
+		}
+		tpk, err := primaryKeyOf(target)
+		if nil != err {
+			return err
+		}
+		values, keys, err := keyValues(r.table, items, pk.FieldName)
+		if nil != err {
+			return err
+		}
+		if len(values) < 1 {
+			return nil
+		}
+		jt := &Table{entity: joinTable(r.Through), schema: r.table.schema}
+		rows, err := session.Table(jt, r.ThroughKey[0], r.ThroughKey[1]).Where(r.ThroughKey[0], values, "IN").All()
+		if nil != err {
+			return err
+		}
+		links := make(map[string][]string)
+		var refs []interface{}
+		seen := make(map[string]bool)
+		for rows.Next() {
+			var k, ref interface{}
+			if e := rows.Scan(&k, &ref); nil != e {
+				rows.Close()
+				return e
+			}
+			rk := relationKey(ref)
+			links[relationKey(k)] = append(links[relationKey(k)], rk)
+			if !seen[rk] {
+				seen[rk] = true
+				if b, ok := ref.([]byte); ok {
+					ref = string(b)
+				}
+				refs = append(refs, ref)
+			}
+		}
+		rows.Close()
+		if e := rows.Err(); nil != e {
+			return e
+		}
+		found, err := fetchTargets(session, target, tpk.FieldName, refs)
+		if nil != err {
+			return err
+		}
+		for i, v := range items {
+			var targets []reflect.Value
+			for _, rk := range links[keys[i]] {
+				targets = append(targets, found[rk]...)
+			}
+			setRelation(r, v, targets)
+		}
+	}
+	return nil
+}
+
+// LoadRelations
+// Which eager loads relations of name into dest, a pointer to an entity or a slice of
+// entities of table. Each relation is loaded with one query (two for MANY TO MANY)
+// for all entities.
+func (session *Session) LoadRelations(table *Table, dest interface{}, names ...string) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("pointer required to load relations")
+	}
+	v = v.Elem()
+	var items []reflect.Value
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			x := v.Index(i)
+			if x.Kind() == reflect.Ptr {
+				x = x.Elem()
+			}
+			items = append(items, x)
+		}
+	case reflect.Struct:
+		items = append(items, v)
+	default:
+		return errors.New("entity or slice of entities required to load relations")
+	}
+	if len(items) < 1 {
+		return nil
+	}
+	for _, name := range names {
+		r, ok := table.GetRelation(name)
+		if !ok {
+			return errors.New("Invalid relation:" + name)
+		}
+		if e := r.load(session, items); nil != e {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package xql_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+type Author struct {
+	Id    int    `xql:"type=serial,pk"`
+	Name  string `xql:"size=32"`
+	Posts []Post `xql:"rel=has_many"`
+}
+
+func (a Author) TableName() string { return "authors" }
+
+type Post struct {
+	Id     int     `xql:"type=serial,pk"`
+	Title  string  `xql:"size=64"`
+	Writer int     `xql:"type=integer,fk=authors.id"`
+	Author *Author `xql:"rel=belongs_to"`
+	Labels []Label `xql:"rel=many_to_many,through=post_labels(post_id,label_id)"`
+}
+
+func (p Post) TableName() string { return "posts" }
+
+type Label struct {
+	Id   int    `xql:"type=serial,pk"`
+	Name string `xql:"size=32"`
+}
+
+func (l Label) TableName() string { return "labels" }
+
+type Review struct {
+	Id     int   `xql:"type=serial,pk"`
+	PostId int   `xql:"type=integer"`
+	Post   *Post `xql:"rel=belongs_to"`
+}
+
+func (r Review) TableName() string { return "reviews" }
+
+var (
+	AuthorTable = xql.DeclareTable(Author{})
+	PostTable   = xql.DeclareTable(Post{})
+	LabelTable  = xql.DeclareTable(Label{})
+	ReviewTable = xql.DeclareTable(Review{})
+)
+
+func TestLoadBelongsTo(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(rows("id,name", []driver.Value{int64(10), "ann"}, []driver.Value{int64(11), "bob"}))
+	posts := []Post{{Id: 1, Writer: 10}, {Id: 2, Writer: 11}, {Id: 3, Writer: 10}, {Id: 4, Writer: 12}}
+	if err := session.LoadRelations(PostTable, &posts, "Author"); nil != err {
+		t.Fatal(err)
+	}
+	// Key is the column with fk tag referencing authors.
+	assertStatement(t, fdb.Last(), `FROM authors WHERE "id" IN ($1,$2,$3)`, int64(10), int64(11), int64(12))
+	if posts[0].Author.Name != "ann" || posts[1].Author.Name != "bob" || posts[2].Author.Name != "ann" {
+		t.Errorf("authors = %v %v %v", posts[0].Author, posts[1].Author, posts[2].Author)
+	}
+	if nil != posts[3].Author {
+		t.Errorf("author of missing = %v", posts[3].Author)
+	}
+}
+
+func TestLoadBelongsToDefaultKey(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(rows("id,title", []driver.Value{int64(5), "hello"}))
+	review := Review{Id: 1, PostId: 5}
+	if err := session.LoadRelations(ReviewTable, &review, "Post"); nil != err {
+		t.Fatal(err)
+	}
+	assertStatement(t, fdb.Last(), `FROM posts WHERE "id" IN ($1)`, int64(5))
+	if nil == review.Post || review.Post.Title != "hello" {
+		t.Errorf("post = %v", review.Post)
+	}
+}
+
+func TestLoadHasMany(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(rows("id,title,writer",
+		[]driver.Value{int64(1), "x", int64(10)},
+		[]driver.Value{int64(2), "y", int64(10)},
+		[]driver.Value{int64(3), "z", int64(11)}))
+	authors := []*Author{{Id: 10}, {Id: 11}, {Id: 12}}
+	if err := session.LoadRelations(AuthorTable, &authors, "Posts"); nil != err {
+		t.Fatal(err)
+	}
+	assertStatement(t, fdb.Last(), `FROM posts WHERE writer IN ($1,$2,$3)`, int64(10), int64(11), int64(12))
+	if len(authors[0].Posts) != 2 || len(authors[1].Posts) != 1 || authors[1].Posts[0].Title != "z" {
+		t.Errorf("posts = %v %v", authors[0].Posts, authors[1].Posts)
+	}
+	if nil == authors[2].Posts || len(authors[2].Posts) != 0 {
+		t.Errorf("posts of author without = %#v", authors[2].Posts)
+	}
+}
+
+func TestLoadManyToMany(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(rows("post_id,label_id",
+		[]driver.Value{int64(1), int64(100)},
+		[]driver.Value{int64(1), int64(101)},
+		[]driver.Value{int64(2), int64(101)}),
+		rows("id,name", []driver.Value{int64(100), "go"}, []driver.Value{int64(101), "sql"}))
+	posts := []Post{{Id: 1}, {Id: 2}, {Id: 3}}
+	if err := session.LoadRelations(PostTable, &posts, "Labels"); nil != err {
+		t.Fatal(err)
+	}
+	ss := fdb.Statements()
+	if len(ss) != 2 {
+		t.Fatalf("statements = %v", fdb.SQL())
+	}
+	assertStatement(t, ss[0], `SELECT "post_id","label_id" FROM post_labels WHERE post_id IN ($1,$2,$3)`, int64(1), int64(2), int64(3))
+	assertStatement(t, ss[1], `FROM labels WHERE "id" IN ($1,$2)`, int64(100), int64(101))
+	if len(posts[0].Labels) != 2 || posts[0].Labels[1].Name != "sql" || len(posts[1].Labels) != 1 || len(posts[2].Labels) != 0 {
+		t.Errorf("labels = %v %v %v", posts[0].Labels, posts[1].Labels, posts[2].Labels)
+	}
+}
+
+func TestPreload(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(rows("id,title,writer", []driver.Value{int64(1), "x", int64(10)}),
+		rows("id,name", []driver.Value{int64(10), "ann"}))
+	posts, err := xql.From[Post](session, PostTable).Preload("Author").All()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || nil == posts[0].Author || posts[0].Author.Name != "ann" {
+		t.Errorf("posts = %v", posts)
+	}
+}
+
+func TestLoadRelationsErrors(t *testing.T) {
+	session, _ := openSession(t)
+	var post Post
+	if err := session.LoadRelations(PostTable, post, "Author"); nil == err {
+		t.Error("expected error of non pointer")
+	}
+	if err := session.LoadRelations(PostTable, &post, "Missing"); nil == err {
+		t.Error("expected error of invalid relation")
+	}
+}
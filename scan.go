@@ -0,0 +1,249 @@
+package xql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UnmatchedPolicy
+// Which decides what to do with result columns not matching any struct field on Scan.
+type UnmatchedPolicy uint8
+
+const (
+	UnmatchedIgnore UnmatchedPolicy = iota
+	UnmatchedError
+)
+
+// structPlan
+// Which maps column names to the index path of struct fields.
+type structPlan struct {
+	names map[string][]int
+	lower map[string][]int
+}
+
+var structPlans sync.Map
+
+// fieldNames
+// Which returns the names a struct field matches, by precedence: xql name, json tag and field name.
+func fieldNames(f reflect.StructField) []string {
+	var names []string
+	name := Camel2Underscore(f.Name)
+	if props, e := ParseProperties(f.Tag.Get("xql")); nil == e {
+		if n, ok := props.GetString("name"); ok && n != "" {
+			name = n
+		}
+	}
+	names = append(names, name)
+	if jtag := strings.Split(f.Tag.Get("json"), ",")[0]; jtag != "" && jtag != "-" {
+		names = append(names, jtag)
+	}
+	return append(names, f.Name)
+}
+
+func (p *structPlan) add(name string, path []int) {
+	if _, ok := p.names[name]; !ok {
+		p.names[name] = path
+	}
+	if _, ok := p.lower[strings.ToLower(name)]; !ok {
+		p.lower[strings.ToLower(name)] = path
+	}
+}
+
+func (p *structPlan) build(t reflect.Type, prefix []int) {
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.Split(f.Tag.Get("xql"), ",")[0] == "-" || isRelationField(f) {
+			continue
+		}
+		if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !isScannable(ft) {
+				embedded = append(embedded, f)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		path := append(append([]int{}, prefix...), i)
+		for _, n := range fieldNames(f) {
+			p.add(n, path)
+		}
+	}
+	// Fields of embedded structs are shadowed by outer fields.
+	for _, f := range embedded {
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		p.build(ft, append(append([]int{}, prefix...), f.Index...))
+	}
+}
+
+func getStructPlan(t reflect.Type) *structPlan {
+	if p, ok := structPlans.Load(t); ok {
+		return p.(*structPlan)
+	}
+	p := &structPlan{names: make(map[string][]int), lower: make(map[string][]int)}
+	p.build(t, nil)
+	structPlans.Store(t, p)
+	return p
+}
+
+func (p *structPlan) lookup(name string) ([]int, bool) {
+	if path, ok := p.names[name]; ok {
+		return path, true
+	}
+	path, ok := p.lower[strings.ToLower(name)]
+	return path, ok
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+var timeType = reflect.TypeOf(time.Time{})
+
+func isScannable(t reflect.Type) bool {
+	return t == timeType || reflect.PointerTo(t).Implements(scannerType)
+}
+
+// structDest
+// Which returns the struct value a destination points to, if it should be scanned by column names.
+func structDest(d interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(d)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return v, false
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct || isScannable(v.Type()) {
+		return v, false
+	}
+	return v, true
+}
+
+// fieldByPath
+// Which returns the field of path, allocating nil embedded pointers on the way.
+func fieldByPath(v reflect.Value, path []int) reflect.Value {
+	for i, x := range path {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// scanTargets
+// Which returns the scan destinations of columns in struct v.
+func scanTargets(v reflect.Value, columns []string, policy UnmatchedPolicy) ([]interface{}, error) {
+	plan := getStructPlan(v.Type())
+	targets := make([]interface{}, len(columns))
+	var unmatched []string
+	for i, c := range columns {
+		if path, ok := plan.lookup(c); ok {
+			targets[i] = fieldByPath(v, path).Addr().Interface()
+		} else {
+			unmatched = append(unmatched, c)
+			targets[i] = new(interface{})
+		}
+	}
+	if len(unmatched) > 0 && policy == UnmatchedError {
+		return nil, fmt.Errorf("unmatched columns for %s: %s", v.Type().String(), strings.Join(unmatched, ","))
+	}
+	return targets, nil
+}
+
+// Record
+// An ordered result row of dynamic shape.
+type Record struct {
+	Columns []string
+	Values  []interface{}
+}
+
+// Get
+// Which returns the value of column name.
+func (r Record) Get(name string) (interface{}, bool) {
+	for i, c := range r.Columns {
+		if c == name {
+			return r.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+// Map
+// Which returns the record as a map keyed by column name.
+func (r Record) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.Columns))
+	for i, c := range r.Columns {
+		m[c] = r.Values[i]
+	}
+	return m
+}
+
+// naturalValue
+// Which converts a raw driver value to a natural Go type according to the database type.
+func naturalValue(v interface{}, ct *sql.ColumnType) (interface{}, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return v, nil
+	}
+	switch strings.ToUpper(ct.DatabaseTypeName()) {
+	case "BYTEA", "BLOB", "BINARY", "VARBINARY":
+		return append([]byte{}, b...), nil
+	case "JSON", "JSONB":
+		var x interface{}
+		if e := json.Unmarshal(b, &x); nil != e {
+			return nil, e
+		}
+		return x, nil
+	}
+	return string(b), nil
+}
+
+// scanRecord
+// Which scans current row of rows into a Record. Columns declared in table with a
+// sql.Scanner type, like HSTORE or arrays, are decoded with that type.
+func scanRecord(rows *sql.Rows, table *Table) (Record, error) {
+	types, err := rows.ColumnTypes()
+	if nil != err {
+		return Record{}, err
+	}
+	r := Record{Columns: make([]string, len(types)), Values: make([]interface{}, len(types))}
+	targets := make([]interface{}, len(types))
+	for i, ct := range types {
+		r.Columns[i] = ct.Name()
+		if nil != table {
+			if c, ok := table.GetColumn(ct.Name()); ok && reflect.PointerTo(c.Type).Implements(scannerType) {
+				targets[i] = reflect.New(c.Type).Interface()
+				continue
+			}
+		}
+		targets[i] = new(interface{})
+	}
+	if e := rows.Scan(targets...); nil != e {
+		return Record{}, e
+	}
+	for i, t := range targets {
+		if x, ok := t.(*interface{}); ok {
+			v, e := naturalValue(*x, types[i])
+			if nil != e {
+				return Record{}, fmt.Errorf("column %s: %v", r.Columns[i], e)
+			}
+			r.Values[i] = v
+		} else {
+			r.Values[i] = reflect.ValueOf(t).Elem().Interface()
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,105 @@
+package xql_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+type Audit struct {
+	Created time.Time `json:"created_at"`
+	Editor  string
+}
+
+type bookSummary struct {
+	*Audit
+	Id     int    `xql:"name=book_id"`
+	Title  string `json:"heading"`
+	Copies int64
+	Editor string `xql:"-"`
+	Note   sql.NullString
+}
+
+func TestScanStruct(t *testing.T) {
+	session, fdb := openSession(t)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fdb.Push(rows("book_id,heading,COPIES,created_at,editor,note",
+		[]driver.Value{int64(7), "Go", int64(3), created, "ann", nil}))
+	xrows, err := session.QueryRows("SELECT ...")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer xrows.Close()
+	if !xrows.Next() {
+		t.Fatal("no row")
+	}
+	var s bookSummary
+	if err := xrows.Scan(&s); nil != err {
+		t.Fatal(err)
+	}
+	if s.Id != 7 || s.Title != "Go" || s.Copies != 3 || s.Note.Valid {
+		t.Errorf("scanned %+v", s)
+	}
+	if nil == s.Audit || !s.Created.Equal(created) || s.Audit.Editor != "ann" || s.Editor != "" {
+		t.Errorf("embedded %+v, editor %q", s.Audit, s.Editor)
+	}
+}
+
+func TestScanUnmatched(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(rows("id,title,total", []driver.Value{int64(1), "Go", int64(9)}))
+	var b Book
+	if err := session.Table(BookTable).Columns("id", "title", "count(*) AS total").One().Scan(&b); nil != err {
+		t.Fatalf("ignored = %v", err)
+	}
+	if b.Id != 1 || b.Title != "Go" {
+		t.Errorf("scanned %+v", b)
+	}
+
+	fdb.Push(rows("id,title,total", []driver.Value{int64(1), "Go", int64(9)}))
+	err := session.Table(BookTable).Unmatched(xql.UnmatchedError).One().Scan(&b)
+	if nil == err || !strings.Contains(err.Error(), "total") {
+		t.Errorf("error = %v, want unmatched total", err)
+	}
+
+	fdb.Push(rows("id,extra", []driver.Value{int64(1), "x"}))
+	xrows, err := session.QueryRows("SELECT id, extra FROM books")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer xrows.Close()
+	xrows.Next()
+	if err := xrows.Unmatched(xql.UnmatchedError).Scan(&b); nil == err {
+		t.Error("raw query unmatched error expected")
+	}
+}
+
+func TestScanProjection(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(rows("title,n", []driver.Value{"Go", int64(2)}, []driver.Value{"SQL", int64(1)}))
+	type titleCount struct {
+		Title string
+		Count int `xql:"name=n"`
+	}
+	var got []titleCount
+	for x, err := range xql.Iterate[titleCount](session.QueryRows("SELECT title, count(*) AS n FROM books GROUP BY title")) {
+		if nil != err {
+			t.Fatal(err)
+		}
+		got = append(got, x)
+	}
+	if len(got) != 2 || got[0] != (titleCount{"Go", 2}) || got[1] != (titleCount{"SQL", 1}) {
+		t.Errorf("got %+v", got)
+	}
+	// Scanners and plain destinations are scanned by position.
+	fdb.Push(rows("title,n", []driver.Value{"Go", int64(2)}))
+	var title sql.NullString
+	var n int
+	if err := session.Table(BookTable).One().Scan(&title, &n); nil != err || title.String != "Go" || n != 2 {
+		t.Errorf("positional = %v %d %v", title, n, err)
+	}
+}
@@ -0,0 +1,101 @@
+package xql
+
+import "fmt"
+
+// Scope
+// A reusable query fragment, which takes a QuerySet and returns it refined.
+//
+//	active := func(qs xql.QuerySet) xql.QuerySet { return qs.Where("status", "active") }
+type Scope func(QuerySet) QuerySet
+
+// AddDefaultScope
+// Which registers scopes applied to every QuerySet of the table, unless Unscoped.
+// Filters of a default scope are grouped and AND-ed with other filters when the
+// statement is built, so they are not affected by filters joined with Or.
+func (t *Table) AddDefaultScope(scopes ...Scope) *Table {
+	t.defaultScopes = append(t.defaultScopes, scopes...)
+	return t
+}
+
+// AddScope
+// Which registers a named scope of the table, to be applied with QuerySet.Scopes.
+func (t *Table) AddScope(name string, scope Scope) *Table {
+	if nil == t.scopes {
+		t.scopes = make(map[string]Scope)
+	}
+	t.scopes[name] = scope
+	return t
+}
+
+// GetScope
+// Which returns the named scope of name.
+func (t Table) GetScope(name string) (Scope, bool) {
+	s, ok := t.scopes[name]
+	return s, ok
+}
+
+// Unscoped
+// Which disables the default scopes of table for the QuerySet.
+func (qs QuerySet) Unscoped() QuerySet {
+	qs.unscoped = true
+	return qs
+}
+
+// Scopes
+// Which applies named scopes of table in order. Filters added by each scope are
+// grouped and AND-ed with the filters of QuerySet.
+func (qs QuerySet) Scopes(names ...string) QuerySet {
+	for _, name := range names {
+		scope, ok := qs.table.GetScope(name)
+		if !ok {
+			panic(fmt.Sprintf("Scope '%s' not defined on table '%s'!", name, qs.table.TableName()))
+		}
+		qs = qs.Apply(scope)
+	}
+	return qs
+}
+
+// Apply
+// Which applies scope functions to the QuerySet like named scopes.
+func (qs QuerySet) Apply(scopes ...Scope) QuerySet {
+	for _, scope := range scopes {
+		filters := qs.filters
+		qs.filters = nil
+		qs = scope(qs)
+		switch {
+		case len(qs.filters) < 1:
+			qs.filters = filters
+		case len(filters) > 0:
+			if hasOr(filters) {
+				filters = []QueryFilter{{Group: filters}}
+			}
+			qs.filters = append(filters[:len(filters):len(filters)], QueryFilter{Group: qs.filters})
+		}
+	}
+	return qs
+}
+
+func hasOr(filters []QueryFilter) bool {
+	for _, f := range filters {
+		if f.Condition == ConditionOr {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeFilters
+// Which returns the filters of default scopes of table, each grouped.
+func (qs QuerySet) scopeFilters() []QueryFilter {
+	if qs.unscoped {
+		return nil
+	}
+	var filters []QueryFilter
+	for _, scope := range qs.table.defaultScopes {
+		sqs := scope(QuerySet{session: qs.session, table: qs.table, offset: -1, limit: -1})
+		if len(sqs.filters) > 0 {
+			filters = append(filters, QueryFilter{Group: sqs.filters})
+		}
+	}
+	return filters
+}
@@ -0,0 +1,96 @@
+package xql_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/archsh/go.xql"
+)
+
+type Ticket struct {
+	Id       int    `xql:"type=serial,pk"`
+	TenantId int    `xql:"type=integer"`
+	Status   string `xql:"size=16"`
+	Region   string `xql:"size=16"`
+}
+
+func (t Ticket) TableName() string { return "tickets" }
+
+var TicketTable = xql.DeclareTable(Ticket{}).
+	AddDefaultScope(func(qs xql.QuerySet) xql.QuerySet { return qs.Where("tenant_id", 7) }).
+	AddScope("open", func(qs xql.QuerySet) xql.QuerySet { return qs.Where("status", "open") }).
+	AddScope("west", func(qs xql.QuerySet) xql.QuerySet {
+		return qs.Where("region", "us-west").Or("region", "eu-west")
+	})
+
+func TestDefaultScope(t *testing.T) {
+	session, fdb := openSession(t)
+	cases := []struct {
+		name string
+		run  func() error
+		want string
+		args []interface{}
+	}{
+		{"select", func() error {
+			_, err := session.Table(TicketTable, "id").Where("status", "open").All()
+			return err
+		}, `SELECT "id" FROM tickets WHERE (tenant_id = $1) AND (status = $2)`, []interface{}{int64(7), "open"}},
+		{"or kept inside", func() error {
+			_, err := session.Table(TicketTable, "id").Where("status", "open").Or("status", "new").All()
+			return err
+		}, `SELECT "id" FROM tickets WHERE (tenant_id = $1) AND (status = $2 OR status = $3)`, []interface{}{int64(7), "open", "new"}},
+		{"count", func() error {
+			_, err := session.Table(TicketTable).Count()
+			return err
+		}, `SELECT COUNT("id") FROM tickets WHERE (tenant_id = $1)`, []interface{}{int64(7)}},
+		{"update", func() error {
+			_, err := session.Table(TicketTable).Where("id", 1).Update(map[string]interface{}{"status": "closed"})
+			return err
+		}, `UPDATE tickets SET status=$1 WHERE (tenant_id = $2) AND ("id" = $3)`, []interface{}{"closed", int64(7), int64(1)}},
+		{"delete", func() error {
+			_, err := session.Table(TicketTable).Where("id", 1).Delete()
+			return err
+		}, `DELETE FROM tickets WHERE (tenant_id = $1) AND ("id" = $2)`, []interface{}{int64(7), int64(1)}},
+		{"unscoped", func() error {
+			_, err := session.Table(TicketTable, "id").Unscoped().Where("id", 1).All()
+			return err
+		}, `SELECT "id" FROM tickets WHERE "id" = $1`, []interface{}{int64(1)}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fdb.Reset()
+			if c.name == "count" {
+				fdb.Push(rows("count", []driver.Value{int64(0)}))
+			}
+			if err := c.run(); nil != err {
+				t.Fatal(err)
+			}
+			assertStatement(t, fdb.Last(), c.want, c.args...)
+		})
+	}
+}
+
+func TestNamedScopes(t *testing.T) {
+	session, fdb := openSession(t)
+	if _, err := session.Table(TicketTable, "id").Where("id", 3, ">").Scopes("open", "west").All(); nil != err {
+		t.Fatal(err)
+	}
+	assertStatement(t, fdb.Last(),
+		`SELECT "id" FROM tickets WHERE (tenant_id = $1) AND ("id" > $2 AND (status = $3) AND (region = $4 OR region = $5))`,
+		int64(7), int64(3), "open", "us-west", "eu-west")
+
+	recent := func(qs xql.QuerySet) xql.QuerySet { return qs.Where("id", 100, ">=") }
+	if _, err := session.Table(TicketTable, "id").Unscoped().Scopes("west").Apply(recent).All(); nil != err {
+		t.Fatal(err)
+	}
+	assertStatement(t, fdb.Last(),
+		`SELECT "id" FROM tickets WHERE (region = $1 OR region = $2) AND ("id" >= $3)`,
+		"us-west", "eu-west", int64(100))
+
+	defer func() {
+		if nil == recover() {
+			t.Error("expected panic of unknown scope")
+		}
+	}()
+	session.Table(TicketTable).Scopes("closed")
+}
@@ -1,6 +1,7 @@
 package xql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -8,24 +9,125 @@ import (
 )
 
 type Session struct {
-	driverName string
-	dialect    IDialect
-	db         *sql.DB
-	tx         *sql.Tx
-	verbose    bool
+	driverName   string
+	dialect      IDialect
+	db           *sql.DB
+	tx           *sql.Tx
+	verbose      bool
+	tags         QueryTags
+	tagAt        TagPosition
+	tagLimits    *tagLimiter
+	clock        func() time.Time
+	precision    time.Duration
+	location     *time.Location
+	interceptors []Interceptor
+	op           *Operation
+	ctx          context.Context
+}
+
+// Tag
+// Which returns a copy of session attaching a tag to every statement it issues,
+// the session itself is not changed. The copy shares the database and the
+// transaction open at the time, see QueryTags for values allowed.
+func (session *Session) Tag(key, value string) *Session {
+	tagged := *session
+	tagged.tags = session.tags.merge(QueryTags{key: value})
+	return &tagged
+}
+
+// LimitTag
+// Which limits the count of distinct values the tag key takes in statements of the
+// session and copies made after, like request ids tagged per request. Once max
+// values are taken, a warning is logged and the tag is left out of statements for
+// new values, previous values are still rendered.
+func (session *Session) LimitTag(key string, max int) {
+	if nil == session.tagLimits {
+		session.tagLimits = &tagLimiter{limits: map[string]int{}, values: map[string]map[string]struct{}{}}
+	}
+	session.tagLimits.Lock()
+	defer session.tagLimits.Unlock()
+	session.tagLimits.limits[key] = max
+	if _, ok := session.tagLimits.values[key]; !ok {
+		session.tagLimits.values[key] = map[string]struct{}{}
+	}
+}
+
+// SetTagPosition
+// Which set where the tags comment is placed, TagSuffix by default.
+func (session *Session) SetTagPosition(position TagPosition) {
+	session.tagAt = position
+}
+
+// SetContext
+// Which set the context of session, passed to context-aware permission checks like TableReadableContext.
+func (session *Session) SetContext(ctx context.Context) {
+	session.ctx = ctx
+}
+
+// Context
+// Which returns the context of session, context.Background() if not set.
+func (session *Session) Context() context.Context {
+	if nil == session.ctx {
+		return context.Background()
+	}
+	return session.ctx
+}
+
+// SetClock
+// Which set the time source of automatic timestamps, time.Now by default.
+func (session *Session) SetClock(clock func() time.Time) {
+	session.clock = clock
+}
+
+// SetTimePrecision
+// Which set the precision automatic timestamps are truncated to, e.g. time.Microsecond.
+func (session *Session) SetTimePrecision(precision time.Duration) {
+	session.precision = precision
+}
+
+// SetTimeLocation
+// Which set the location of automatic timestamps, like time.UTC or time.Local.
+// Times are kept in the location of the clock if not set.
+func (session *Session) SetTimeLocation(location *time.Location) {
+	session.location = location
+}
+
+// Now
+// Which returns current time of the session clock, with precision and location applied.
+func (session *Session) Now() time.Time {
+	var t time.Time
+	if nil != session.clock {
+		t = session.clock()
+	} else {
+		t = time.Now()
+	}
+	if session.precision > 0 {
+		t = t.Truncate(session.precision)
+	}
+	if nil != session.location {
+		t = t.In(session.location)
+	}
+	return t
+}
+
+func (session *Session) annotate(query string, tags QueryTags) string {
+	tags = session.tags.merge(tags)
+	if nil != session.tagLimits {
+		tags = session.tagLimits.filter(tags)
+	}
+	return tags.Annotate(query, session.tagAt)
 }
 
 func (session *Session) getDialect() IDialect {
 	if nil != session.dialect {
 		return session.dialect
 	}
-	if s, ok := builtinDialects[session.driverName]; ok {
-		session.dialect = s
-		return s
-	} else {
+	// Not cached on session, which may be used concurrently.
+	s, ok := builtinDialects[session.driverName]
+	if !ok {
 		panic(fmt.Sprintf("Dialect '%s' not registered! ", session.driverName))
 	}
-	return nil
+	return s
 }
 
 func (session *Session) Drop(table *Table, force bool) error {
@@ -74,28 +176,7 @@ func (session *Session) Close() {
 func (session *Session) Table(table *Table, columns ...interface{}) QuerySet {
 	qs := QuerySet{session: session, offset: -1, limit: -1}
 	qs.table = table
-	if len(columns) > 0 {
-		for i, c := range columns {
-			if qc, ok := c.(QueryColumn); ok {
-				qs.queries = append(qs.queries, qc)
-			} else if qcn, ok := c.(string); ok {
-				if col, ok := qs.table.GetColumn(qcn); !ok {
-					//panic("Invalid column name:" + qcn)
-					qs.queries = append(qs.queries, QueryColumn{FieldName: qcn, Alias: fmt.Sprintf("aa%d", i)})
-				} else {
-					qs.queries = append(qs.queries, QueryColumn{FieldName: col.FieldName, Alias: col.FieldName})
-				}
-			} else {
-				panic("Unsupported parameter type!")
-			}
-
-		}
-	} else {
-		//for _, col := range qs.table.MappedColumns {
-		//    qs.queries = append(qs.queries, QueryColumn{FieldName:col.FieldName, Alias:col.FieldName})
-		//}
-	}
-	return qs
+	return qs.Columns(columns...)
 }
 
 func (session *Session) Begin() error {
@@ -135,6 +216,10 @@ func logTiming(t1 time.Time, msg string, params ...interface{}) {
 }
 
 func (session *Session) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return session.exec(session.annotate(query, nil), args...)
+}
+
+func (session *Session) exec(query string, args ...interface{}) (sql.Result, error) {
 	if session.verbose {
 		t1 := time.Now()
 		defer logTiming(t1, "Session.Exec:", query, args)
@@ -154,6 +239,10 @@ func (session *Session) Exec(query string, args ...interface{}) (sql.Result, err
 }
 
 func (session *Session) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return session.query(session.annotate(query, nil), args...)
+}
+
+func (session *Session) query(query string, args ...interface{}) (*sql.Rows, error) {
 	if session.verbose {
 		t1 := time.Now()
 		defer logTiming(t1, "Session.Query:", query, args)
@@ -172,7 +261,21 @@ func (session *Session) Query(query string, args ...interface{}) (*sql.Rows, err
 	//return nil, nil
 }
 
+// QueryRows
+// Which runs a raw query and returns XRows, to be scanned or iterated with Iterate.
+func (session *Session) QueryRows(query string, args ...interface{}) (*XRows, error) {
+	rows, err := session.Query(query, args...)
+	if nil != err {
+		return nil, err
+	}
+	return &XRows{rows: rows}, nil
+}
+
 func (session *Session) QueryRow(query string, args ...interface{}) *sql.Row {
+	return session.queryRow(session.annotate(query, nil), args...)
+}
+
+func (session *Session) queryRow(query string, args ...interface{}) *sql.Row {
 	if session.verbose {
 		t1 := time.Now()
 		defer logTiming(t1, "Session.doQueryRaw:", query, args)
@@ -0,0 +1,28 @@
+package xql_test
+
+import (
+	"testing"
+)
+
+func TestSessionTag(t *testing.T) {
+	session, fdb := openSession(t)
+	tagged := session.Tag("route", "/books")
+	if _, err := tagged.Exec("DELETE FROM books"); nil != err {
+		t.Fatal(err)
+	}
+	if s := lastSQL(t, fdb); s != "DELETE FROM books /*route='%2Fbooks'*/" {
+		t.Errorf("tagged = %s", s)
+	}
+	if _, err := session.Exec("DELETE FROM books"); nil != err {
+		t.Fatal(err)
+	}
+	if s := lastSQL(t, fdb); s != "DELETE FROM books" {
+		t.Errorf("tag leaked into session: %s", s)
+	}
+	if _, err := tagged.Table(BookTable).Tag("caller", "x.go:1").Where("id", 1).Delete(); nil != err {
+		t.Fatal(err)
+	}
+	if s := lastSQL(t, fdb); s != `DELETE FROM books WHERE "id" = $1 /*caller='x.go%3A1',route='%2Fbooks'*/` {
+		t.Errorf("merged = %s", s)
+	}
+}
@@ -0,0 +1,69 @@
+package xql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+type Memo struct {
+	Id      int        `xql:"type=serial,pk"`
+	Text    string     `xql:"type=text"`
+	Updated time.Time  `xql:"type=timestamp,autoupdate"`
+	Deleted *time.Time `xql:"type=timestamp,softdelete,nullable"`
+	Rev     int        `xql:"type=integer,version"`
+}
+
+func (m Memo) TableName() string { return "memos" }
+
+var MemoTable = xql.DeclareTable(Memo{})
+
+var fixedNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func openClockSession(t *testing.T) (*xql.Session, func() string) {
+	session, fdb := openSession(t)
+	session.SetClock(func() time.Time { return fixedNow })
+	return session, func() string { return lastSQL(t, fdb) }
+}
+
+func TestSoftDelete(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func(xql.QuerySet) (int64, error)
+		want string
+	}{
+		{"delete", func(q xql.QuerySet) (int64, error) { return q.Where("id", 1).Delete() },
+			`UPDATE memos SET deleted=$1, updated=$2, rev=rev+$3 WHERE deleted IS NULL AND ("id" = $4)`},
+		{"hard delete", func(q xql.QuerySet) (int64, error) { return q.Where("id", 1).HardDelete() },
+			`DELETE FROM memos WHERE "id" = $1`},
+		{"hard delete deleted", func(q xql.QuerySet) (int64, error) { return q.OnlyDeleted().HardDelete() },
+			`DELETE FROM memos WHERE deleted IS NOT NULL`},
+		{"restore", func(q xql.QuerySet) (int64, error) { return q.Where("id", 1).Restore() },
+			`UPDATE memos SET deleted=$1, updated=$2, rev=rev+$3 WHERE deleted IS NOT NULL AND ("id" = $4)`},
+		{"select", func(q xql.QuerySet) (int64, error) { return q.Count() },
+			`SELECT COUNT("id") FROM memos WHERE deleted IS NULL`},
+		{"select with deleted", func(q xql.QuerySet) (int64, error) { return q.WithDeleted().Count() },
+			`SELECT COUNT("id") FROM memos`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			session, last := openClockSession(t)
+			_, _ = c.run(session.Table(MemoTable))
+			if s := last(); s != c.want {
+				t.Errorf("got  %s\nwant %s", s, c.want)
+			}
+		})
+	}
+}
+
+func TestRestoreTouches(t *testing.T) {
+	session, fdb := openSession(t)
+	session.SetClock(func() time.Time { return fixedNow })
+	if _, err := session.Table(MemoTable).Restore(); nil != err {
+		t.Fatal(err)
+	}
+	if args := fdb.Last().Args; len(args) != 3 || nil != args[0] || args[1] != fixedNow || args[2] != int64(1) {
+		t.Errorf("args = %v", args)
+	}
+}
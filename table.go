@@ -3,15 +3,19 @@ package xql
 // Table ...
 // Struct defined for a table object.
 type Table struct {
-	columns     []*Column
-	constraints []*Constraint
-	indexes     []*Index
-	primaryKeys []*Column
-	mColumns    map[string]*Column
-	xColumns    map[string]*Column
-	jColumns    map[string]*Column
-	entity      TableIdentified
-	schema      string
+	columns       []*Column
+	constraints   []*Constraint
+	indexes       []*Index
+	primaryKeys   []*Column
+	relations     []*Relation
+	mColumns      map[string]*Column
+	xColumns      map[string]*Column
+	jColumns      map[string]*Column
+	entity        TableIdentified
+	schema        string
+	defaultScopes []Scope
+	scopes        map[string]Scope
+	interceptors  []Interceptor
 }
 
 // TableIdentified which make sure struct have a method TableName()
@@ -109,6 +113,41 @@ func (t Table) GetPrimaryKeys() []*Column {
 	return t.primaryKeys
 }
 
+// SoftDeleteColumn
+// Which returns the column tagged with 'softdelete'.
+func (t Table) SoftDeleteColumn() (*Column, bool) {
+	for _, c := range t.columns {
+		if c.SoftDelete {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// VersionColumn
+// Which returns the column tagged with 'version'.
+func (t Table) VersionColumn() (*Column, bool) {
+	for _, c := range t.columns {
+		if c.Version {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func (t Table) GetRelations() []*Relation {
+	return t.relations
+}
+
+func (t Table) GetRelation(name string) (*Relation, bool) {
+	for _, r := range t.relations {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
 func (t Table) SetSchema(s string) {
 	t.schema = s
 }
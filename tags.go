@@ -45,13 +45,15 @@ func ParseDottedArgs(s string) (ret []string) {
 			opened &= ^MBraceOpened
 			chars = append(chars, c)
 		case ',':
-			if opened == 0 && len(chars) > 0 {
+			if opened != 0 {
+				// Commas quoted or in braces belong to the value.
+				chars = append(chars, c)
+				continue
+			}
+			if len(chars) > 0 {
 				ret = append(ret, string(chars))
 			}
 			chars = []rune{}
-			/*else {
-				chars = append(chars, c)
-			}*/
 		default:
 			chars = append(chars, c)
 		}
@@ -0,0 +1,39 @@
+package xql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDottedArgs(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"pk", []string{"pk"}},
+		{"size=24,unique,index", []string{"size=24", "unique", "index"}},
+		{"size=24,,index,", []string{"size=24", "index"}},
+		{"check=(age>18 AND age<150),index", []string{"check=(age>18 AND age<150)", "index"}},
+		{"default='a,b',nullable", []string{"default='a,b'", "nullable"}},
+		{`default="x,y"`, []string{`default="x,y"`}},
+		{"default={1,2},type=integer[]", []string{"default={1,2}", "type=integer[]"}},
+		{"default=ARRAY[1,2]", []string{"default=ARRAY[1,2]"}},
+	}
+	for _, c := range cases {
+		if got := ParseDottedArgs(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseDottedArgs(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	p, err := ParseProperties("name=desc,type=text,nullable,check=(a,b)")
+	if nil != err {
+		t.Fatal(err)
+	}
+	want := PropertySet{"name": "desc", "type": "text", "nullable": "t", "check": "(a,b)"}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("ParseProperties = %v, want %v", p, want)
+	}
+}
@@ -0,0 +1,214 @@
+package xql
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// Query
+// A typed query bound to entity type T, which is built on a QuerySet and
+// returns values of T instead of scanning into interface{}.
+//
+//	students, err := xql.From[Student](session, StudentTable).Where("age", 18, ">").All()
+type Query[T any] struct {
+	qs  QuerySet
+	err error
+}
+
+// From
+// Which makes a typed query of table, T must be the entity type the table declared with,
+// otherwise the error is returned by the first call running the query.
+func From[T any](session *Session, table *Table, columns ...interface{}) Query[T] {
+	return Of[T](session.Table(table, columns...))
+}
+
+// Of
+// Which makes a typed query of an existing QuerySet, see From.
+func Of[T any](qs QuerySet) Query[T] {
+	q := Query[T]{qs: qs}
+	if et := reflect.TypeOf((*T)(nil)).Elem(); et != entityType(qs.table.entity) {
+		q.err = fmt.Errorf("type %s does not match entity of table '%s'", et.String(), qs.table.TableName())
+	}
+	return q
+}
+
+// QuerySet
+// Which returns the underlying QuerySet.
+func (q Query[T]) QuerySet() QuerySet {
+	return q.qs
+}
+
+func (q Query[T]) Where(field string, val interface{}, ops ...string) Query[T] {
+	q.qs = q.qs.Where(field, val, ops...)
+	return q
+}
+
+func (q Query[T]) And(field string, val interface{}, ops ...string) Query[T] {
+	q.qs = q.qs.And(field, val, ops...)
+	return q
+}
+
+func (q Query[T]) Or(field string, val interface{}, ops ...string) Query[T] {
+	q.qs = q.qs.Or(field, val, ops...)
+	return q
+}
+
+func (q Query[T]) Filter(cons ...interface{}) Query[T] {
+	q.qs = q.qs.Filter(cons...)
+	return q
+}
+
+func (q Query[T]) OrderBy(orders ...interface{}) Query[T] {
+	q.qs = q.qs.OrderBy(orders...)
+	return q
+}
+
+func (q Query[T]) Offset(offset int64) Query[T] {
+	q.qs = q.qs.Offset(offset)
+	return q
+}
+
+func (q Query[T]) Limit(limit int64) Query[T] {
+	q.qs = q.qs.Limit(limit)
+	return q
+}
+
+func (q Query[T]) Lock(strength LockStrength, wait LockWait, of ...string) Query[T] {
+	q.qs = q.qs.Lock(strength, wait, of...)
+	return q
+}
+
+func (q Query[T]) Tag(key, value string) Query[T] {
+	q.qs = q.qs.Tag(key, value)
+	return q
+}
+
+func (q Query[T]) Preload(names ...string) Query[T] {
+	q.qs = q.qs.Preload(names...)
+	return q
+}
+
+func (q Query[T]) Scopes(names ...string) Query[T] {
+	q.qs = q.qs.Scopes(names...)
+	return q
+}
+
+func (q Query[T]) Unscoped() Query[T] {
+	q.qs = q.qs.Unscoped()
+	return q
+}
+
+func (q Query[T]) Count(cols ...string) (int64, error) {
+	if nil != q.err {
+		return 0, q.err
+	}
+	return q.qs.Count(cols...)
+}
+
+// All
+// Which returns all entities matched.
+func (q Query[T]) All() ([]T, error) {
+	if nil != q.err {
+		return nil, q.err
+	}
+	rows, err := q.qs.All()
+	if nil != err {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []T
+	for rows.Next() {
+		var t T
+		if err := rows.Scan(&t); nil != err {
+			return nil, err
+		}
+		ret = append(ret, t)
+	}
+	if err := rows.Err(); nil != err {
+		return nil, err
+	}
+	if len(q.qs.preloads) > 0 {
+		if err := q.qs.session.LoadRelations(q.qs.table, &ret, q.qs.preloads...); nil != err {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// First
+// Which returns the first entity matched, sql.ErrNoRows if nothing matched.
+func (q Query[T]) First() (T, error) {
+	var t T
+	if nil != q.err {
+		return t, q.err
+	}
+	if err := q.qs.One().Scan(&t); nil != err {
+		return t, err
+	}
+	if len(q.qs.preloads) > 0 {
+		return t, q.qs.session.LoadRelations(q.qs.table, &t, q.qs.preloads...)
+	}
+	return t, nil
+}
+
+// Get
+// Which returns the entity of given primary keys, sql.ErrNoRows if not exists.
+func (q Query[T]) Get(pks ...interface{}) (T, error) {
+	var t T
+	if nil != q.err {
+		return t, q.err
+	}
+	if err := q.qs.Get(pks...).Scan(&t); nil != err {
+		return t, err
+	}
+	if len(q.qs.preloads) > 0 {
+		return t, q.qs.session.LoadRelations(q.qs.table, &t, q.qs.preloads...)
+	}
+	return t, nil
+}
+
+// Iter
+// Which streams the entities matched, the query runs when the iteration starts. See Iterate.
+func (q Query[T]) Iter() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if nil != q.err {
+			var t T
+			yield(t, q.err)
+			return
+		}
+		Iterate[T](q.qs.All())(yield)
+	}
+}
+
+// Iterate
+// Which streams rows as values of T, one at a time. Rows are closed when the
+// iteration ends or breaks, errors of query, scan and iteration are yielded.
+// It takes the results of QuerySet.All or Session.QueryRows directly:
+//
+//	for s, err := range xql.Iterate[Student](session.Table(StudentTable).All()) {
+//	    ...
+//	}
+func Iterate[T any](rows *XRows, err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var t T
+		if nil != err {
+			yield(t, err)
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var v T
+			if e := rows.Scan(&v); nil != e {
+				yield(v, e)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if e := rows.Err(); nil != e {
+			yield(t, e)
+		}
+	}
+}
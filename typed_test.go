@@ -0,0 +1,151 @@
+package xql_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/archsh/go.xql"
+	"github.com/archsh/go.xql/internal/fakedb"
+)
+
+func bookRows(n int, err error) fakedb.Result {
+	r := fakedb.Result{Columns: []string{"id", "title"}, Err: err}
+	for i := 1; i <= n; i++ {
+		r.Rows = append(r.Rows, []driver.Value{int64(i), "book"})
+	}
+	return r
+}
+
+func TestIterate(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(bookRows(3, nil))
+	var ids []int
+	for b, err := range xql.Iterate[Book](session.Table(BookTable).All()) {
+		if nil != err {
+			t.Fatal(err)
+		}
+		ids = append(ids, b.Id)
+	}
+	if len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("ids = %v", ids)
+	}
+	if fdb.Closed() != 1 {
+		t.Errorf("rows closed %d times, want 1", fdb.Closed())
+	}
+}
+
+func TestIterateBreakClosesRows(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(bookRows(3, nil))
+	n := 0
+	for _, err := range xql.From[Book](session, BookTable).Iter() {
+		if nil != err {
+			t.Fatal(err)
+		}
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("iterated %d, want 1", n)
+	}
+	if fdb.Closed() != 1 {
+		t.Errorf("rows closed %d times, want 1", fdb.Closed())
+	}
+}
+
+func TestIterateErrors(t *testing.T) {
+	session, fdb := openSession(t)
+	broken := errors.New("connection reset")
+	fdb.Push(bookRows(2, broken))
+	var got []error
+	for _, err := range xql.Iterate[Book](session.Table(BookTable).All()) {
+		got = append(got, err)
+	}
+	if len(got) != 3 || nil != got[0] || !errors.Is(got[2], broken) {
+		t.Errorf("errors = %v", got)
+	}
+
+	failed := errors.New("syntax error")
+	fdb.Fail = func(string) error { return failed }
+	got = nil
+	for _, err := range xql.Iterate[Book](session.QueryRows("SELECT 1")) {
+		got = append(got, err)
+	}
+	if len(got) != 1 || !errors.Is(got[0], failed) {
+		t.Errorf("errors = %v", got)
+	}
+}
+
+func TestIterateRawQuery(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(bookRows(2, nil))
+	var titles []string
+	for b, err := range xql.Iterate[Book](session.QueryRows("SELECT id, title FROM books")) {
+		if nil != err {
+			t.Fatal(err)
+		}
+		titles = append(titles, b.Title)
+	}
+	if len(titles) != 2 || lastSQL(t, fdb) != "SELECT id, title FROM books" {
+		t.Errorf("titles = %v, sql = %q", titles, lastSQL(t, fdb))
+	}
+}
+
+func TestAllAndFirst(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.Push(bookRows(2, nil), bookRows(1, nil))
+	q := xql.From[Book](session, BookTable, "id", "title").Where("title", "book")
+	all, err := q.All()
+	if nil != err || len(all) != 2 {
+		t.Fatalf("All() = %v, %v", all, err)
+	}
+	first, err := q.First()
+	if nil != err || first.Id != 1 {
+		t.Fatalf("First() = %v, %v", first, err)
+	}
+	if s := lastSQL(t, fdb); s != `SELECT "id","title" FROM books WHERE title = $1 LIMIT 1` {
+		t.Errorf("sql = %s", s)
+	}
+}
+
+func TestTypeMismatch(t *testing.T) {
+	session, fdb := openSession(t)
+	q := xql.From[ArchivedBook](session, BookTable)
+	if _, err := q.All(); nil == err {
+		t.Error("All() expected error")
+	}
+	if _, err := q.Where("id", 1).First(); nil == err {
+		t.Error("First() expected error")
+	}
+	if _, err := q.Get(1); nil == err {
+		t.Error("Get() expected error")
+	}
+	if _, err := xql.Of[*Book](session.Table(BookTable)).Count(); nil == err {
+		t.Error("Count() expected error")
+	}
+	for _, err := range q.Iter() {
+		if nil == err {
+			t.Error("Iter() expected error")
+		}
+	}
+	if n := len(fdb.Statements()); n != 0 {
+		t.Errorf("%d statements issued", n)
+	}
+}
+
+type Magazine struct {
+	Id int `xql:"type=serial,pk"`
+}
+
+func (m Magazine) TableName() string { return "magazines" }
+
+func TestPointerDeclaredEntity(t *testing.T) {
+	session, fdb := openSession(t)
+	table := xql.DeclareTable(&Magazine{})
+	fdb.Push(fakedb.Result{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(7)}}})
+	m, err := xql.From[Magazine](session, table).First()
+	if nil != err || m.Id != 7 {
+		t.Errorf("First() = %v, %v", m, err)
+	}
+}
@@ -2,26 +2,84 @@ package xql
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"reflect"
 )
 
+// Field
+// A nullable value, an invalid Field is written as NULL.
 type Field[T any] sql.Null[T]
 
-func (f Field[T]) UnmarshalJSON(bytes []byte) error {
-	//TODO implement me
-	//panic("implement me")
+// NewField
+// Which makes a valid Field of v.
+func NewField[T any](v T) Field[T] {
+	return Field[T]{V: v, Valid: true}
+}
+
+func (f Field[T]) isNull() bool {
+	return !f.Valid
+}
+
+// Value implements the driver Valuer interface.
+func (f Field[T]) Value() (driver.Value, error) {
+	if !f.Valid {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(f.V)
+}
+
+// Scan implements the sql.Scanner interface, NULL makes the Field invalid.
+func (f *Field[T]) Scan(value interface{}) error {
+	return (*sql.Null[T])(f).Scan(value)
+}
+
+// Declare
+// Which declares the column type inferred from T, or given by tag 'type'.
+func (f Field[T]) Declare(props PropertySet) string {
+	return f.declareFor("", props)
+}
+
+func (f Field[T]) declareFor(dialect string, props PropertySet) string {
+	if d, ok := interface{}(f.V).(TryDeclarable); ok {
+		if _, ok := props.GetString("type"); !ok {
+			s, e := d.TryDeclare(props)
+			if nil != e {
+				panic(e)
+			}
+			return s
+		}
+	}
+	if d, ok := interface{}(f.V).(Declarable); ok {
+		if _, ok := props.GetString("type"); !ok {
+			return d.Declare(props)
+		}
+	}
+	var t T
+	s, e := DeclareFor(dialect, reflect.StructField{Name: "V", Type: reflect.TypeOf(&t).Elem()}, props)
+	if nil != e {
+		panic(e)
+	}
+	return s
+}
+
+// UnmarshalJSON
+// Which makes the Field invalid for null, valid with the value otherwise.
+func (f *Field[T]) UnmarshalJSON(bytes []byte) error {
+	if string(bytes) == "null" {
+		var t T
+		f.V, f.Valid = t, false
+		return nil
+	}
 	var t = new(T)
 	if e := json.Unmarshal(bytes, t); nil != e {
 		return e
-	} else {
-		f.V, f.Valid = *t, true
-		return nil
 	}
+	f.V, f.Valid = *t, true
+	return nil
 }
 
 func (f Field[T]) MarshalJSON() ([]byte, error) {
-	//TODO implement me
-	//panic("implement me")
 	if f.Valid {
 		return json.Marshal(f.V)
 	} else {
@@ -0,0 +1,115 @@
+package xql_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+type Player struct {
+	Id     int               `xql:"type=serial,pk"`
+	Nick   xql.Field[string] `xql:"size=32"`
+	Score  xql.Field[int64]  `json:"score"`
+	Seen   xql.Field[time.Time]
+	Rating xql.Field[float64] `xql:"nullable=false"`
+}
+
+func (p Player) TableName() string { return "players" }
+
+var PlayerTable = xql.DeclareTable(Player{})
+
+func TestFieldDeclare(t *testing.T) {
+	cases := []struct {
+		column   string
+		typ      string
+		nullable bool
+	}{
+		{"nick", "character varying(32)", true},
+		{"score", "bigint", true},
+		{"seen", "timestamp", true},
+		{"rating", "double precision", false},
+	}
+	for _, c := range cases {
+		col, ok := PlayerTable.GetColumn(c.column)
+		if !ok {
+			t.Fatalf("column %s not declared", c.column)
+		}
+		if col.TypeDefine != c.typ || col.Nullable != c.nullable {
+			t.Errorf("%s declared %q nullable %v, want %q nullable %v",
+				c.column, col.TypeDefine, col.Nullable, c.typ, c.nullable)
+		}
+	}
+}
+
+func TestFieldScanValue(t *testing.T) {
+	var f xql.Field[int64]
+	if err := f.Scan(int64(3)); nil != err || !f.Valid || f.V != 3 {
+		t.Errorf("Scan(3) = %+v, %v", f, err)
+	}
+	if v, err := f.Value(); nil != err || v != int64(3) {
+		t.Errorf("Value() = %v, %v", v, err)
+	}
+	if err := f.Scan(nil); nil != err || f.Valid || f.V != 0 {
+		t.Errorf("Scan(NULL) = %+v, %v", f, err)
+	}
+	if v, err := f.Value(); nil != err || nil != v {
+		t.Errorf("invalid Value() = %v, %v, want NULL", v, err)
+	}
+	var s xql.Field[string]
+	if err := s.Scan([]byte("neo")); nil != err || !s.Valid || s.V != "neo" {
+		t.Errorf("Scan(neo) = %+v, %v", s, err)
+	}
+	if err := f.Scan("x"); nil == err {
+		t.Error("Scan of text into Field[int64] should fail")
+	}
+	if v, _ := xql.NewField(int16(2)).Value(); v != int64(2) {
+		t.Errorf("Value() of int16 = %#v, want int64", v)
+	}
+}
+
+func TestFieldJSON(t *testing.T) {
+	p := Player{Nick: xql.NewField("neo"), Score: xql.NewField(int64(0))}
+	bs, err := json.Marshal(p)
+	if nil != err {
+		t.Fatal(err)
+	}
+	want := `{"Id":0,"Nick":"neo","score":0,"Seen":null,"Rating":null}`
+	if string(bs) != want {
+		t.Errorf("Marshal = %s, want %s", bs, want)
+	}
+	var q Player
+	q.Nick = xql.NewField("old")
+	if err := json.Unmarshal([]byte(`{"Nick":null,"score":7,"Rating":1.5}`), &q); nil != err {
+		t.Fatal(err)
+	}
+	if q.Nick.Valid || !q.Score.Valid || q.Score.V != 7 || q.Seen.Valid || q.Rating.V != 1.5 {
+		t.Errorf("Unmarshal = %+v", q)
+	}
+	if err := json.Unmarshal([]byte(`{"score":"x"}`), &q); nil == err {
+		t.Error("Unmarshal of text into Field[int64] should fail")
+	}
+}
+
+func TestFieldFilters(t *testing.T) {
+	session, fdb := openSession(t)
+	cases := []struct {
+		qs   xql.QuerySet
+		want string
+		args []interface{}
+	}{
+		{session.Table(PlayerTable, "id").Where("score", xql.Field[int64]{}),
+			`SELECT "id" FROM players WHERE score IS NULL`, nil},
+		{session.Table(PlayerTable, "id").Where("score", xql.NewField(int64(5))),
+			`SELECT "id" FROM players WHERE score = $1`, []interface{}{int64(5)}},
+		{session.Table(PlayerTable, "id").Filter(xql.IsNull("nick"), xql.IsNotNull("seen")),
+			`SELECT "id" FROM players WHERE nick IS NULL AND seen IS NOT NULL`, nil},
+	}
+	for _, c := range cases {
+		if _, err := c.qs.All(); nil != err {
+			t.Fatal(err)
+		}
+		assertStatement(t, fdb.Last(), c.want, c.args...)
+	}
+}
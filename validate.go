@@ -0,0 +1,288 @@
+package xql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError
+// A validation failure of a column, Rule is one of: nullable, size, min, max, check, pattern, enum.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors
+// Which returned by Insert and Update when values break rules declared in tags.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	var ss []string
+	for _, x := range e {
+		ss = append(ss, x.Error())
+	}
+	return "validation failed: " + strings.Join(ss, "; ")
+}
+
+// rule
+// Which tests a not NULL value of column, returns a message if failed.
+type rule struct {
+	name string
+	test func(v reflect.Value) string
+}
+
+var sizedTypeRex = regexp.MustCompile(`^(?:character varying|varchar|character|char)\((\d+)\)(?:\s|$)`)
+var simpleCheckRex = regexp.MustCompile(`^"?([a-zA-Z_][a-zA-Z0-9_]*)"?\s*(>=|<=|<>|!=|>|<|=)\s*(-?\d+(?:\.\d+)?)$`)
+
+// makeRules
+// Which makes the validation rules of column from its tags:
+//
+//	size=24             length limit of character types, also the default size of varchar
+//	min=0,max=150       numeric range, of numbers and numeric strings like Decimal
+//	check=(age>18)      comparisons of the column with numbers, joined by AND
+//	pattern='^[a-z]+$'  regular expression strings must match
+//	enum=a;b;c          allowed values
+//
+// Parts of check which are not such comparisons are left to the database, and listed
+// in Unvalidated of column. Invalid min, max or pattern panics.
+func makeRules(c *Column) []rule {
+	var rules []rule
+	if m := sizedTypeRex.FindStringSubmatch(strings.ToLower(c.TypeDefine)); nil != m {
+		n, _ := strconv.Atoi(m[1])
+		rules = append(rules, rule{"size", func(v reflect.Value) string {
+			if v.Kind() == reflect.String && utf8.RuneCountInString(v.String()) > n {
+				return fmt.Sprintf("length exceeds %d", n)
+			}
+			return ""
+		}})
+	}
+	for _, k := range []string{"min", "max"} {
+		s, ok := c.GetString(k)
+		if !ok || s == "" {
+			continue
+		}
+		x, ok := new(big.Rat).SetString(s)
+		if !ok {
+			panic(fmt.Sprintf("Invalid %s '%s' of column '%s'!", k, s, c.FieldName))
+		}
+		op := ">="
+		if k == "max" {
+			op = "<="
+		}
+		rules = append(rules, compareRule(k, op, x, s))
+	}
+	if check, ok := c.GetString("check"); ok {
+		var checks []rule
+		checks, c.Unvalidated = checkRules(c, check)
+		rules = append(rules, checks...)
+	}
+	if s, ok := c.GetString("pattern"); ok && s != "" {
+		rex, e := regexp.Compile(strings.Trim(s, "'"))
+		if nil != e {
+			panic(fmt.Sprintf("Invalid pattern '%s' of column '%s': %s", s, c.FieldName, e))
+		}
+		rules = append(rules, rule{"pattern", func(v reflect.Value) string {
+			if v.Kind() == reflect.String && !rex.MatchString(v.String()) {
+				return "does not match pattern " + rex.String()
+			}
+			return ""
+		}})
+	}
+	if values := c.EnumValues(); len(values) > 0 {
+		rules = append(rules, rule{"enum", func(v reflect.Value) string {
+			s := fmt.Sprint(v.Interface())
+			for _, x := range values {
+				if x == s {
+					return ""
+				}
+			}
+			return "must be one of " + strings.Join(values, ",")
+		}})
+	}
+	return rules
+}
+
+var andRex = regexp.MustCompile(`(?i)\s+AND\s+`)
+
+// checkRules
+// Which makes rules of the parts of a check constraint comparing the column with
+// numbers, and returns other parts skipped.
+func checkRules(c *Column, check string) ([]rule, []string) {
+	check = strings.TrimSpace(check)
+	for strings.HasPrefix(check, "(") && strings.HasSuffix(check, ")") {
+		check = strings.TrimSpace(check[1 : len(check)-1])
+	}
+	var rules []rule
+	var skipped []string
+	for _, part := range andRex.Split(check, -1) {
+		part = strings.TrimSpace(part)
+		m := simpleCheckRex.FindStringSubmatch(strings.Trim(part, "()"))
+		if nil == m || m[1] != c.FieldName || strings.Count(part, "(") != strings.Count(part, ")") {
+			skipped = append(skipped, part)
+			continue
+		}
+		x, _ := new(big.Rat).SetString(m[3])
+		rules = append(rules, compareRule("check", m[2], x, m[3]))
+	}
+	return rules, skipped
+}
+
+// ratOf
+// Which returns the exact number of v, false if v is not a number or a numeric string.
+func ratOf(v reflect.Value) (*big.Rat, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetUint64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if r := new(big.Rat); nil != r.SetFloat64(v.Float()) {
+			return r, true
+		}
+	case reflect.String:
+		if decimalRex.MatchString(v.String()) {
+			return new(big.Rat).SetString(v.String())
+		}
+	}
+	return nil, false
+}
+
+func compareRule(name string, op string, x *big.Rat, text string) rule {
+	return rule{name, func(v reflect.Value) string {
+		f, ok := ratOf(v)
+		if !ok {
+			if v.Kind() == reflect.String {
+				return "must be a number"
+			}
+			return ""
+		}
+		switch c := f.Cmp(x); op {
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		case "=":
+			ok = c == 0
+		case "<>", "!=":
+			ok = c != 0
+		}
+		if !ok {
+			return fmt.Sprintf("must be %s %s", op, text)
+		}
+		return ""
+	}}
+}
+
+// ruleValue
+// Which returns the underlying value of x for rules, false if it is NULL.
+func ruleValue(x interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(x)
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		if vr, ok := v.Interface().(driver.Valuer); ok {
+			return valuerValue(vr)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return v, false
+	}
+	if vr, ok := v.Interface().(driver.Valuer); ok {
+		return valuerValue(vr)
+	}
+	return v, true
+}
+
+func valuerValue(vr driver.Valuer) (reflect.Value, bool) {
+	x, e := vr.Value()
+	if nil != e {
+		// Left to the database.
+		return reflect.Value{}, true
+	}
+	if nil == x {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(x), true
+}
+
+// validate
+// Which validates value x of column.
+func (c *Column) validate(x interface{}) []FieldError {
+	v, ok := ruleValue(x)
+	if !ok {
+		if !c.Nullable {
+			return []FieldError{{Field: c.FieldName, Rule: "nullable", Message: "must not be null"}}
+		}
+		return nil
+	} else if !v.IsValid() {
+		return nil
+	}
+	var errs []FieldError
+	for _, r := range c.rules {
+		if msg := r.test(v); msg != "" {
+			errs = append(errs, FieldError{Field: c.FieldName, Rule: r.name, Message: msg})
+		}
+	}
+	return errs
+}
+
+// validateColumns
+// Which validates values of cols set with '=', returns ValidationErrors if any failed.
+func (t *Table) validateColumns(cols []UpdateColumn) error {
+	var errs ValidationErrors
+	for _, uc := range cols {
+		if uc.Operator != "=" {
+			continue
+		}
+		if c, ok := t.GetColumn(uc.Field); ok {
+			errs = append(errs, c.validate(uc.Value)...)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate
+// Which validates the entity obj against rules declared in tags, for columns given
+// or columns written on insert, returns ValidationErrors if any failed. Without
+// columns given, NULL of a NOT NULL column without default, which is left out on
+// insert, fails too. Primary keys are left to the database.
+func (t *Table) Validate(obj interface{}, columns ...string) error {
+	r := reflect.Indirect(reflect.ValueOf(obj))
+	var cols []UpdateColumn
+	if len(columns) < 1 {
+		for _, c := range t.columns {
+			v := r.FieldByName(c.ElemName)
+			if !c.Omitted(v, true) {
+				columns = append(columns, c.FieldName)
+			} else if v.IsValid() && isNullValue(v) && !c.Nullable && nil == c.Default && !c.Generated && !c.PrimaryKey {
+				columns = append(columns, c.FieldName)
+			}
+		}
+	}
+	for _, n := range columns {
+		if c, ok := t.GetColumn(n); ok && !c.Generated {
+			cols = append(cols, UpdateColumn{Field: c.FieldName, Operator: "=", Value: r.FieldByName(c.ElemName).Interface()})
+		}
+	}
+	return t.validateColumns(cols)
+}
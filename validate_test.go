@@ -0,0 +1,144 @@
+package xql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type account struct {
+	Id      int      `xql:"type=serial,pk"`
+	Name    string   `xql:"size=8"`
+	Code    Varchar  `xql:"size=4,nullable,pattern='^[A-Z]+$'"`
+	Age     int      `xql:"type=integer,min=18,max=150"`
+	Score   float64  `xql:"type=real,check=(score >= 0 AND score < 10.5)"`
+	Level   int      `xql:"type=integer,check=(level > 0 AND level % 2 = 1)"`
+	Balance Decimal  `xql:"precision=10,scale=2,min=0,max=1000.50"`
+	Rate    *Numeric `xql:"precision=5,scale=3,max=0.5"`
+	Kind    string   `xql:"size=8,enum=a;b"`
+	Note    *string  `xql:"type=text"`
+}
+
+func (a account) TableName() string { return "accounts" }
+
+var accountTable = DeclareTable(account{})
+
+func validAccount() account {
+	return account{Name: "ann", Age: 20, Level: 1, Balance: "10.00", Kind: "a"}
+}
+
+func TestValidateRules(t *testing.T) {
+	rate := func(s string) *Numeric { n := Numeric(s); return &n }
+	cases := []struct {
+		name   string
+		modify func(*account)
+		rules  []string
+	}{
+		{"valid", func(a *account) {}, nil},
+		{"size", func(a *account) { a.Name = "ninechars" }, []string{"name:size"}},
+		{"size runes", func(a *account) { a.Name = "学生学生学生学生" }, nil},
+		{"pattern", func(a *account) { a.Code = "ab" }, []string{"code:pattern"}},
+		{"pattern and size", func(a *account) { a.Code = "abcde" }, []string{"code:size", "code:pattern"}},
+		{"min", func(a *account) { a.Age = 17 }, []string{"age:min"}},
+		{"max", func(a *account) { a.Age = 151 }, []string{"age:max"}},
+		{"check lower", func(a *account) { a.Score = -0.5 }, []string{"score:check"}},
+		{"check upper", func(a *account) { a.Score = 10.5 }, []string{"score:check"}},
+		{"check partial", func(a *account) { a.Level = 2 }, nil},
+		{"check partial failed", func(a *account) { a.Level = -1 }, []string{"level:check"}},
+		{"decimal min", func(a *account) { a.Balance = "-0.01" }, []string{"balance:min"}},
+		{"decimal max", func(a *account) { a.Balance = "1000.51" }, []string{"balance:max"}},
+		{"decimal max equal", func(a *account) { a.Balance = "1000.5" }, nil},
+		{"numeric pointer", func(a *account) { a.Rate = rate("0.501") }, []string{"rate:max"}},
+		{"numeric pointer valid", func(a *account) { a.Rate = rate("0.500") }, nil},
+		{"enum", func(a *account) { a.Kind = "c" }, []string{"kind:enum"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := validAccount()
+			c.modify(&a)
+			err := accountTable.Validate(a)
+			var got []string
+			var ve ValidationErrors
+			if errors.As(err, &ve) {
+				for _, x := range ve {
+					got = append(got, x.Field+":"+x.Rule)
+				}
+			} else if nil != err {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != strings.Join(c.rules, ",") {
+				t.Errorf("failed rules = %v, want %v (%v)", got, c.rules, err)
+			}
+		})
+	}
+}
+
+func TestValidateNullable(t *testing.T) {
+	err := accountTable.Validate(validAccount(), "note", "rate")
+	if nil != err {
+		t.Errorf("NULL of nullable columns: %v", err)
+	}
+	err = accountTable.validateColumns([]UpdateColumn{{Field: "name", Operator: "=", Value: nil}})
+	var ve ValidationErrors
+	if !errors.As(err, &ve) || ve[0].Rule != "nullable" {
+		t.Errorf("NULL of not nullable column: %v", err)
+	}
+}
+
+type pledge struct {
+	Id   int     `xql:"type=serial,pk"`
+	Must *string `xql:"type=text,nullable=false"`
+	Memo *string `xql:"type=text,nullable=false,default=''"`
+}
+
+func (p pledge) TableName() string { return "pledges" }
+
+func TestValidateOmittedNotNull(t *testing.T) {
+	table := DeclareTable(pledge{})
+	err := table.Validate(pledge{})
+	var ve ValidationErrors
+	if !errors.As(err, &ve) || len(ve) != 1 || ve[0].Field != "must" || ve[0].Rule != "nullable" {
+		t.Errorf("NULL of not nullable column left out: %v", err)
+	}
+	must := "x"
+	if err := table.Validate(pledge{Must: &must}); nil != err {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestUnvalidated(t *testing.T) {
+	c, _ := accountTable.GetColumn("level")
+	if len(c.Unvalidated) != 1 || c.Unvalidated[0] != "level % 2 = 1" {
+		t.Errorf("unvalidated = %q", c.Unvalidated)
+	}
+	c, _ = accountTable.GetColumn("score")
+	if len(c.Unvalidated) != 0 {
+		t.Errorf("unvalidated = %q", c.Unvalidated)
+	}
+}
+
+type badPattern struct {
+	Id   int    `xql:"type=serial,pk"`
+	Name string `xql:"size=8,pattern='^[a-z'"`
+}
+
+func (b badPattern) TableName() string { return "bad_patterns" }
+
+type badRange struct {
+	Id  int `xql:"type=serial,pk"`
+	Age int `xql:"type=integer,min=ten"`
+}
+
+func (b badRange) TableName() string { return "bad_ranges" }
+
+func TestDeclarationErrors(t *testing.T) {
+	if _, err := TryDeclareTable(badPattern{}); nil == err || !strings.Contains(err.Error(), "pattern") {
+		t.Errorf("pattern error = %v", err)
+	}
+	if _, err := TryDeclareTable(badRange{}); nil == err || !strings.Contains(err.Error(), "min") {
+		t.Errorf("min error = %v", err)
+	}
+	if table, err := TryDeclareTable(account{}); nil != err || nil == table {
+		t.Errorf("TryDeclareTable() = %v, %v", table, err)
+	}
+}
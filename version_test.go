@@ -0,0 +1,108 @@
+package xql_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/archsh/go.xql"
+)
+
+func TestVersionBumped(t *testing.T) {
+	cases := []struct {
+		name string
+		run  func(xql.QuerySet) (int64, error)
+		want string
+	}{
+		{"map", func(q xql.QuerySet) (int64, error) {
+			return q.Where("id", 1).Update(map[string]interface{}{"text": "x"})
+		}, `UPDATE memos SET "text"=$1, updated=$2, rev=rev+$3 WHERE deleted IS NULL AND ("id" = $4)`},
+		{"columns", func(q xql.QuerySet) (int64, error) {
+			return q.Where("id", 1).Update([]xql.UpdateColumn{{Field: "text", Operator: "=", Value: "x"}})
+		}, `UPDATE memos SET "text"=$1, updated=$2, rev=rev+$3 WHERE deleted IS NULL AND ("id" = $4)`},
+		{"version given", func(q xql.QuerySet) (int64, error) {
+			return q.Where("id", 1).Update(map[string]interface{}{"rev": 7})
+		}, `UPDATE memos SET rev=$1, updated=$2 WHERE deleted IS NULL AND ("id" = $3)`},
+		{"entity", func(q xql.QuerySet) (int64, error) {
+			return q.Where("id", 1).Update(Memo{Id: 1, Text: "x", Rev: 3})
+		}, `UPDATE memos SET "text"=$1, updated=$2, rev=rev+$3 WHERE deleted IS NULL AND rev = $4 AND ("id" = $5)`},
+		{"soft delete entity", func(q xql.QuerySet) (int64, error) {
+			return q.Delete(Memo{Id: 1, Rev: 3})
+		}, `UPDATE memos SET deleted=$1, updated=$2, rev=rev+$3 WHERE deleted IS NULL AND "id" = $4 AND rev = $5`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			session, last := openClockSession(t)
+			if _, err := c.run(session.Table(MemoTable)); nil != err {
+				t.Fatal(err)
+			}
+			if s := last(); s != c.want {
+				t.Errorf("got  %s\nwant %s", s, c.want)
+			}
+		})
+	}
+}
+
+// Draft
+// Declared with a pointer, so that versions of entities written are updated.
+type Draft struct {
+	Id      int        `xql:"type=serial,pk"`
+	Text    string     `xql:"type=text"`
+	Deleted *time.Time `xql:"type=timestamp,softdelete,nullable"`
+	Rev     int        `xql:"type=integer,version"`
+}
+
+func (d Draft) TableName() string { return "drafts" }
+
+var DraftTable = xql.DeclareTable(&Draft{})
+
+func TestStaleObject(t *testing.T) {
+	session, fdb := openSession(t)
+	fdb.SetAffected(0)
+	memo := &Draft{Id: 1, Text: "x", Rev: 3}
+	if _, err := session.Table(DraftTable).Update(memo); !errors.Is(err, xql.ErrStaleObject) {
+		t.Errorf("entity update = %v", err)
+	}
+	if _, err := session.Table(DraftTable).Delete(memo); !errors.Is(err, xql.ErrStaleObject) {
+		t.Errorf("entity delete = %v", err)
+	}
+	if memo.Rev != 3 {
+		t.Errorf("version of stale = %d", memo.Rev)
+	}
+	// Nothing matched without a version filter is not stale.
+	if n, err := session.Table(MemoTable).Where("id", 1).Update(map[string]interface{}{"text": "x"}); nil != err || n != 0 {
+		t.Errorf("map update = %d, %v", n, err)
+	}
+	if n, err := session.Table(MemoTable).Where("id", 1).Delete(); nil != err || n != 0 {
+		t.Errorf("filter delete = %d, %v", n, err)
+	}
+	if n, err := session.Table(BookTable).Update(Book{Id: 1, Title: "x"}); nil != err || n != 0 {
+		t.Errorf("unversioned update = %d, %v", n, err)
+	}
+
+	fdb.SetAffected(1)
+	if _, err := session.Table(DraftTable).Update(memo); nil != err || memo.Rev != 4 {
+		t.Errorf("update = %v, version %d", err, memo.Rev)
+	}
+	if _, err := session.Table(DraftTable).Delete(memo); nil != err || memo.Rev != 5 {
+		t.Errorf("delete = %v, version %d", err, memo.Rev)
+	}
+}
+
+type Sheet struct {
+	Id      int    `xql:"type=serial,pk"`
+	Text    string `xql:"type=text"`
+	Version int    `xql:"type=integer,version"`
+}
+
+func (s Sheet) TableName() string { return "sheets" }
+
+var SheetTable = xql.DeclareTable(Sheet{})
+
+func TestVersionKeyword(t *testing.T) {
+	session, fdb := openSession(t)
+	if _, err := session.Table(SheetTable).Where("id", 1).Update(map[string]interface{}{"text": "x"}); nil != err {
+		t.Fatal(err)
+	}
+	assertStatement(t, fdb.Last(), `UPDATE sheets SET "text"=$1, "version"="version"+$2 WHERE "id" = $3`, "x", int64(1), int64(1))
+}
@@ -2,7 +2,7 @@ package xql
 
 import (
     "database/sql"
-    //"fmt"
+    "fmt"
 )
 
 func MakeSession(db *sql.DB, driverName string, verbose ...bool) *Session {
@@ -13,8 +13,24 @@ func MakeSession(db *sql.DB, driverName string, verbose ...bool) *Session {
     return sess
 }
 
+// TryDeclareTable
+// Which like DeclareTable, returns an error instead of panic if tags of entity are invalid.
+func TryDeclareTable(entity TableIdentified, schema ...string) (t *Table, err error) {
+    defer func() {
+        if r := recover(); nil != r {
+            if e, ok := r.(error); ok {
+                err = e
+            } else {
+                err = fmt.Errorf("%v", r)
+            }
+        }
+    }()
+    return DeclareTable(entity, schema...), nil
+}
+
 // DeclareTable
-// Which declare a new Table instance according to a given entity.
+// Which declare a new Table instance according to a given entity, panics if tags
+// of entity are invalid.
 func DeclareTable(entity TableIdentified, schema ...string) *Table {
     var skips []string
     if et, ok := entity.(TableIgnored); ok {
@@ -23,6 +39,10 @@ func DeclareTable(entity TableIdentified, schema ...string) *Table {
     t := &Table{
         entity:  entity,
     }
+    // Known to columns, for names of enum types in the schema.
+    if len(schema) > 0 {
+        t.schema = schema[0]
+    }
     t.columns = makeColumns(t, entity, false, skips...)
     t.xColumns = make(map[string]*Column)
     t.jColumns = make(map[string]*Column)
@@ -32,9 +52,6 @@ func DeclareTable(entity TableIdentified, schema ...string) *Table {
         t.mColumns[f.ElemName] = f
         t.jColumns[f.JTag] = f
     }
-    if len(schema) > 0 {
-        t.schema = schema[0]
-    }
     //fmt.Println(">>> Table:", t.TableName())
     //for _, c := range t.columns {
     //    fmt.Println(">>> Column:", c.FieldName, c.ElemName, c.TypeDefine)
@@ -61,6 +78,9 @@ func DeclareTable(entity TableIdentified, schema ...string) *Table {
         }
     }
 
+    t.relations = makeRelations(t, entity)
+    registerTable(t)
+
     return t
 }
 